@@ -0,0 +1,554 @@
+// Package bqschemagen generates the Go structs for BigQuery table schemas, which bigquery.RowIterator can load the rows into.
+//
+// The schemas are read from a SchemaSource such as the BigQuery API, `bq show` JSON files or CREATE TABLE statements:
+//
+//	g, err := bqschemagen.New(bqschemagen.Options{NullableMode: bqschemagen.NullableModeNull})
+//	if err != nil {
+//		return err
+//	}
+//	code, err := g.Generate(ctx, bqschemagen.NewBigQuerySource(client, "dataset"))
+package bqschemagen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"cloud.google.com/go/bigquery"
+	"golang.org/x/tools/imports"
+)
+
+// ErrColumnOverrideNotFound is returned when a column override names a table or column that does not exist.
+var ErrColumnOverrideNotFound = errors.New("column override target not found")
+
+// GeneratedCodeComment is the first line of the generated code, which marks the files generated by bqschema-gen-go.
+// NOTE(ginokent): ref. https://golang.org/s/generatedcode
+const GeneratedCodeComment = "// Code generated by go run github.com/ginokent/bqschema-gen-go; DO NOT EDIT."
+
+// Generator generates the Go structs for the table schemas.
+// A Generator has no state between the Generate calls.
+type Generator struct {
+	opts         Options
+	initialisms  map[string]bool
+	filter       *tableFilter
+	template     *template.Template
+	tagTemplates []tagTemplate
+}
+
+// New returns the Generator configured by opts, or an error if opts is invalid.
+func New(opts Options) (g *Generator, err error) {
+	opts = opts.withDefaults()
+	if err = opts.validate(); err != nil {
+		return nil, fmt.Errorf("opts.validate: %w", err)
+	}
+	filter, err := newTableFilter(opts.Include, opts.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("newTableFilter: %w", err)
+	}
+	g = &Generator{opts: opts, initialisms: newInitialisms(opts.Initialisms), filter: filter}
+	g.template, err = g.newTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("newTemplate: %w", err)
+	}
+	g.tagTemplates, err = g.newTagTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("newTagTemplates: %w", err)
+	}
+	return g, nil
+}
+
+// Dataset is the SchemaSource of the tables in a BigQuery dataset, which GenerateDatasets namespaces the struct names by.
+type Dataset struct {
+	// ProjectID is only used for the namespace if the same DatasetID is given for more than one project.
+	ProjectID string
+	DatasetID string
+	Source    SchemaSource
+}
+
+// Generate generates the code for the tables in source that Options.Include and Options.Exclude select.
+// The tables that can not be generated, or can not be read by a 4xx error of the Google APIs such as 404, are skipped with a warning,
+// but Generate fails with the other errors of reading a table, such as ErrRetriesExhausted, ErrShardSchemaMismatch or an error of a local file,
+// and with ErrColumnOverrideNotFound or ErrIdentifierCollision.
+// With Options.Strict, Generate fails with TableErrors of all skipped tables instead.
+func (g *Generator) Generate(ctx context.Context, source SchemaSource) (generatedCode []byte, err error) {
+	datasetTables, err := g.generateTables(ctx, []Dataset{{Source: source}}, []string{""}, false)
+	if err != nil {
+		return nil, fmt.Errorf("generateTables: %w", err)
+	}
+	return g.generateFile(g.opts.PackageName, datasetTables[0], true)
+}
+
+// GenerateDatasets generates the code for all tables in datasets into one package.
+// The struct names are prefixed with the dataset ID (e.g. `SalesUsers` for `sales.users`), or with the project ID and the dataset ID
+// if the same dataset ID is given for more than one project, so that the tables of the same name in different datasets do not collide.
+// The column overrides apply to the datasets following Options.ColumnTypes, and fail with ErrColumnOverrideNotFound
+// if the table or the column is in none of the datasets they apply to. The errors are handled like Generate.
+func (g *Generator) GenerateDatasets(ctx context.Context, datasets []Dataset) (generatedCode []byte, err error) {
+	namespaces, err := g.datasetNamespaces(datasets)
+	if err != nil {
+		return nil, fmt.Errorf("datasetNamespaces: %w", err)
+	}
+	datasetTables, err := g.generateTables(ctx, datasets, namespaces, false)
+	if err != nil {
+		return nil, fmt.Errorf("generateTables: %w", err)
+	}
+	var tables []*TableData
+	for _, dataTables := range datasetTables {
+		tables = append(tables, dataTables...)
+	}
+	return g.generateFile(g.opts.PackageName, tables, true)
+}
+
+// GeneratePackages generates the code for the tables of each dataset in datasets into its own package named packageNames[i].
+// Unlike GenerateDatasets, the struct names are not prefixed with the dataset ID.
+// The column overrides are handled like GenerateDatasets.
+// The errors are handled like Generate.
+func (g *Generator) GeneratePackages(ctx context.Context, datasets []Dataset, packageNames []string) (generatedCodes [][]byte, err error) {
+	datasetTables, err := g.generatePackageTables(ctx, datasets, packageNames)
+	if err != nil {
+		return nil, fmt.Errorf("generatePackageTables: %w", err)
+	}
+
+	generatedCodes = make([][]byte, len(datasets))
+	for i, tables := range datasetTables {
+		generatedCodes[i], err = g.generateFile(packageNames[i], tables, true)
+		if err != nil {
+			return nil, fmt.Errorf("generateFile: datasetID=%s, %w", datasets[i].DatasetID, err)
+		}
+	}
+	return generatedCodes, nil
+}
+
+// generatePackageTables generates the code for the tables of each dataset in datasets for GeneratePackages and GeneratePackageFiles.
+func (g *Generator) generatePackageTables(ctx context.Context, datasets []Dataset, packageNames []string) (datasetTables [][]*TableData, err error) {
+	if len(packageNames) != len(datasets) {
+		return nil, fmt.Errorf("packageNames do not match datasets: len(datasets)=%d, len(packageNames)=%d", len(datasets), len(packageNames))
+	}
+	for _, packageName := range packageNames {
+		if !token.IsIdentifier(packageName) || packageName == "_" {
+			return nil, fmt.Errorf("invalid package name: %s", packageName)
+		}
+	}
+	if _, err = g.datasetNamespaces(datasets); err != nil {
+		return nil, fmt.Errorf("datasetNamespaces: %w", err)
+	}
+
+	return g.generateTables(ctx, datasets, make([]string, len(datasets)), true)
+}
+
+// datasetNamespaces returns the Go names that prefix the struct names of the tables in datasets.
+func (g *Generator) datasetNamespaces(datasets []Dataset) (namespaces []string, err error) {
+	if len(datasets) == 0 {
+		return nil, fmt.Errorf("datasets is empty")
+	}
+
+	projects := make(map[string]map[string]bool)
+	for _, dataset := range datasets {
+		if dataset.DatasetID == "" || dataset.Source == nil {
+			return nil, fmt.Errorf("DatasetID and Source are required: %#v", dataset)
+		}
+		if projects[dataset.DatasetID] == nil {
+			projects[dataset.DatasetID] = make(map[string]bool)
+		}
+		if projects[dataset.DatasetID][dataset.ProjectID] {
+			return nil, fmt.Errorf("duplicate dataset: %s.%s", dataset.ProjectID, dataset.DatasetID)
+		}
+		projects[dataset.DatasetID][dataset.ProjectID] = true
+	}
+
+	namespaces = make([]string, len(datasets))
+	for i, dataset := range datasets {
+		name := dataset.DatasetID
+		if len(projects[dataset.DatasetID]) > 1 {
+			name = dataset.ProjectID + "_" + dataset.DatasetID
+		}
+		namespaces[i] = g.toGoName(name)
+	}
+
+	return namespaces, nil
+}
+
+// generateTables generates the code for all tables in datasets in the order of the table IDs,
+// prefixing the struct names of datasets[i] with namespaces[i]. datasetTables[i] are the tables of datasets[i].
+// The struct names of all datasets are declared in a package, or in a package per dataset if packages is true.
+func (g *Generator) generateTables(ctx context.Context, datasets []Dataset, namespaces []string, packages bool) (datasetTables [][]*TableData, err error) {
+	// NOTE(ginokent): filter the table IDs before the schemas are read, which saves the API calls of the BigQuery API.
+	r := g.newRequester()
+	datasetEntries := make([][]tableEntry, len(datasets))
+	datasetTableIDs := make([][]string, len(datasets))
+	var allTableIDs, skippedByFilter []string
+	var tableErrors TableErrors
+	for i, dataset := range datasets {
+		var tableIDs, excluded []string
+		err = r.do(ctx, func() (err error) {
+			tableIDs, err = dataset.Source.TableIDs(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("source.TableIDs: datasetID=%s, %w", dataset.DatasetID, err)
+		}
+		tableIDs, excluded = g.FilterTableIDs(tableIDs)
+		for _, tableID := range excluded {
+			skippedByFilter = append(skippedByFilter, tableFullID("", dataset.DatasetID, tableID))
+		}
+		datasetEntries[i] = g.tableEntries(tableIDs)
+		for _, entry := range datasetEntries[i] {
+			datasetTableIDs[i] = append(datasetTableIDs[i], entry.tableID)
+		}
+		allTableIDs = append(allTableIDs, datasetTableIDs[i]...)
+	}
+
+	overrides := g.resolveColumnOverrides(datasets)
+	if err = validateColumnOverrideTables(overrides, datasets, datasetTableIDs); err != nil {
+		return nil, fmt.Errorf("validateColumnOverrideTables: %w", err)
+	}
+
+	// NOTE(ginokent): read the schemas concurrently, and generate the code in the order of the table IDs so that the code is deterministic.
+	var jobs []fetchJob
+	var jobDatasets []int
+	for i, dataset := range datasets {
+		for _, entry := range datasetEntries[i] {
+			for _, tableID := range entry.fetchTableIDs() {
+				jobs = append(jobs, fetchJob{source: dataset.Source, tableID: tableID})
+				jobDatasets = append(jobDatasets, i)
+			}
+		}
+	}
+	results, err := g.fetchTables(ctx, r, jobs)
+	if err != nil {
+		return nil, fmt.Errorf("fetchTables: %w", err)
+	}
+	datasetFetched := make([]map[string]fetchResult, len(datasets))
+	for i := range datasets {
+		datasetFetched[i] = make(map[string]fetchResult)
+	}
+	for j, job := range jobs {
+		datasetFetched[jobDatasets[j]][job.tableID] = results[j]
+	}
+
+	// NOTE(ginokent): read all tables before the code is generated, because a column override is validated against the tables of the table ID in all datasets.
+	datasetRead := make([]map[string]*TableSchema, len(datasets))
+	for i, dataset := range datasets {
+		datasetRead[i] = make(map[string]*TableSchema)
+		for _, entry := range datasetEntries[i] {
+			var table *TableSchema
+			table, err = g.readTable(entry, datasetFetched[i])
+			if err != nil {
+				// NOTE(ginokent): Strict reports these errors with the errors of the other tables.
+				if !g.opts.Strict && !isSkippable(err) {
+					return nil, fmt.Errorf("readTable: %w", err)
+				}
+				warnln(g.opts.Logger, "readTable: tableID="+tableFullID(dataset.ProjectID, dataset.DatasetID, entry.tableID)+", "+err.Error())
+				tableErrors = append(tableErrors, &TableError{TableID: tableFullID("", dataset.DatasetID, entry.tableID), Err: fmt.Errorf("readTable: %w", err)})
+				continue
+			}
+			datasetRead[i][entry.tableID] = table
+		}
+	}
+
+	if err = validateColumnOverrideColumns(overrides, datasets, datasetRead); err != nil {
+		return nil, fmt.Errorf("validateColumnOverrideColumns: %w", err)
+	}
+
+	datasetTables = make([][]*TableData, len(datasets))
+	typeScope := g.newIdentifierScope("package")
+	for i, dataset := range datasets {
+		if packages {
+			typeScope = g.newIdentifierScope("package " + tableFullID(dataset.ProjectID, "", dataset.DatasetID))
+		}
+		datasetGenerator := g.withColumnOverrides(overrides, dataset)
+		for _, entry := range datasetEntries[i] {
+			table, ok := datasetRead[i][entry.tableID]
+			if !ok {
+				continue
+			}
+
+			var data *TableData
+			data, err = datasetGenerator.generateTableSchemaCode(table, namespaces[i], typeScope)
+			if err != nil {
+				if errors.Is(err, ErrIdentifierCollision) {
+					return nil, fmt.Errorf("generateTableSchemaCode: %w", err)
+				}
+				warnln(g.opts.Logger, "generateTableSchemaCode: "+err.Error())
+				tableErrors = append(tableErrors, &TableError{TableID: tableFullID("", dataset.DatasetID, entry.tableID), Err: fmt.Errorf("generateTableSchemaCode: %w", err)})
+				continue
+			}
+
+			datasetTables[i] = append(datasetTables[i], data)
+		}
+	}
+
+	skippedByError := make([]string, len(tableErrors))
+	for i, tableError := range tableErrors {
+		skippedByError[i] = tableError.TableID
+	}
+	infoln(g.opts.Logger, generateSummary(len(allTableIDs)-len(skippedByError), skippedByFilter, skippedByError))
+	if g.opts.Strict && len(tableErrors) > 0 {
+		return nil, tableErrors
+	}
+
+	return datasetTables, nil
+}
+
+// generateFile generates a file of the package packageName for tables by the "file" template. goGenerate adds the go:generate directive,
+// which must be in only one file of the package so that `go generate` runs once.
+func (g *Generator) generateFile(packageName string, tables []*TableData, goGenerate bool) (generatedCode []byte, err error) {
+	data := &FileData{PackageName: packageName, GoGenerate: goGenerate, Tables: tables}
+	uniq := make(map[string]bool)
+	for _, table := range tables {
+		for _, pkg := range table.Imports {
+			if !uniq[pkg] {
+				uniq[pkg] = true
+				data.Imports = append(data.Imports, pkg)
+			}
+		}
+	}
+
+	code, err := g.executeTemplate("file", data)
+	if err != nil {
+		return nil, fmt.Errorf("executeTemplate: %w", err)
+	}
+
+	if g.opts.Debug {
+		fmt.Fprintln(g.opts.DebugWriter, ">>>> DEBUG >>>>>>>>>>>>>>>>")
+		fmt.Fprintln(g.opts.DebugWriter, code)
+		fmt.Fprintln(g.opts.DebugWriter, "<<<< DEBUG <<<<<<<<<<<<<<<<")
+	}
+
+	gen := []byte(code)
+
+	genFmt, err := format.Source(gen)
+	if err != nil {
+		return nil, fmt.Errorf("format.Source: %w", err)
+	}
+
+	if g.opts.Debug {
+		fmt.Fprintln(g.opts.DebugWriter, ">>>> DEBUG >>>>>>>>>>>>>>>>")
+		fmt.Fprintln(g.opts.DebugWriter, string(genFmt))
+		fmt.Fprintln(g.opts.DebugWriter, "<<<< DEBUG <<<<<<<<<<<<<<<<")
+	}
+
+	genImports, err := imports.Process("", genFmt, nil)
+	if err != nil {
+		return nil, fmt.Errorf("imports.Process: %w", err)
+	}
+
+	return genImports, nil
+}
+
+// generateSummary returns the log line of the numbers of the generated tables and the skipped tables.
+func generateSummary(generated int, skippedByFilter, skippedByError []string) (summary string) {
+	summary = "generated " + strconv.Itoa(generated) + " tables"
+	summary = summary + ", skipped " + strconv.Itoa(len(skippedByFilter)) + " tables by filter"
+	if len(skippedByFilter) > 0 {
+		summary = summary + " (" + strings.Join(skippedByFilter, ", ") + ")"
+	}
+	summary = summary + ", skipped " + strconv.Itoa(len(skippedByError)) + " tables by error"
+	if len(skippedByError) > 0 {
+		summary = summary + " (" + strings.Join(skippedByError, ", ") + ")"
+	}
+	return summary
+}
+
+// generateTableSchemaCode generates the struct types for table by the "table" template, whose struct name is prefixed with namespace.
+// The type names are declared in typeScope, which is shared by all tables in the generated package.
+func (g *Generator) generateTableSchemaCode(table *TableSchema, namespace string, typeScope *identifierScope) (data *TableData, err error) {
+	tableID := table.TableID
+	if len(tableID) == 0 {
+		return nil, fmt.Errorf("tableID is empty. *TableSchema struct dump: %#v", table)
+	}
+
+	original := tableID
+	if namespace != "" && table.FullID != "" {
+		original = table.FullID
+	}
+
+	data = &TableData{Table: table, Description: commentLines(table.Description)}
+	data.StructName, err = typeScope.declare(namespace+g.toGoName(tableID), original)
+	if err != nil {
+		return nil, fmt.Errorf("typeScope.declare: %w", err)
+	}
+
+	data.Structs, data.Imports, err = g.generateStructs(data.StructName, tableID, table.Fields, typeScope)
+	if err != nil {
+		return nil, fmt.Errorf("generateStructs: tableID=%s, %w", tableID, err)
+	}
+
+	data.Code, err = g.executeTemplate("table", data)
+	if err != nil {
+		return nil, fmt.Errorf("executeTemplate: tableID=%s, %w", tableID, err)
+	}
+
+	return data, nil
+}
+
+// generateStructs returns the struct named structName for fields, followed by the structs for the RECORD fields.
+// The structs for RECORD fields are named structName + field name, and are generated recursively after the parent struct.
+// columnPath is the dot-separated path of fields (e.g. `table` or `table.record`) that keys the column overrides.
+// structName must already be declared in typeScope, and the nested struct names are declared in it.
+func (g *Generator) generateStructs(structName string, columnPath string, fields []*FieldSchema, typeScope *identifierScope) (structs []*StructData, importPackages []string, err error) {
+	if len(fields) == 0 {
+		return nil, nil, fmt.Errorf("fields is empty. structName=%s", structName)
+	}
+
+	fieldScope := g.newIdentifierScope("struct " + structName)
+	tagScopes := g.newTagScopes(structName)
+
+	structData := &StructData{Name: structName, ColumnPath: columnPath}
+	var nestedStructs []*StructData
+
+	for _, fieldSchema := range fields {
+		nullable := fieldSchema.nullable()
+
+		fieldPath := columnPath + "." + fieldSchema.Name
+		fieldName := g.toGoName(fieldSchema.Name)
+		if name, ok := g.opts.ColumnNames[fieldPath]; ok {
+			fieldName = name
+		}
+		fieldName, err = fieldScope.declare(fieldName, fieldSchema.Name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fieldScope.declare: %w", err)
+		}
+
+		var goType GoType
+		override, overridden := g.opts.ColumnTypes[fieldPath]
+		switch {
+		case overridden:
+			// NOTE(ginokent): the column type override is the exact field type, so REPEATED and NULLABLE are not applied.
+			goType = override
+		case fieldSchema.Type == bigquery.RecordFieldType:
+			var nestedStructName string
+			nestedStructName, err = typeScope.declare(structName+fieldName, fieldPath)
+			if err != nil {
+				return nil, nil, fmt.Errorf("typeScope.declare: %w", err)
+			}
+			var nested []*StructData
+			var pkgs []string
+			nested, pkgs, err = g.generateStructs(nestedStructName, fieldPath, fieldSchema.Fields, typeScope)
+			if err != nil {
+				return nil, nil, fmt.Errorf("generateStructs: fieldName=%s, %w", fieldSchema.Name, err)
+			}
+			nested[0].Record, nested[0].Parent = fieldSchema, structName
+			importPackages = append(importPackages, pkgs...)
+			nestedStructs = append(nestedStructs, nested...)
+			goType = GoType{Name: nestedStructName}
+			if nullable {
+				// NOTE(ginokent): a NULL RECORD can be loaded only into a *struct, which is nil, regardless of Options.NullableMode.
+				//                 RowIterator.Next panics if a struct field is NULL. ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/value.go#L253-L260
+				goType.Name = "*" + goType.Name
+			}
+		default:
+			goType, err = g.goType(fieldSchema.Type, nullable)
+			if err != nil {
+				return nil, nil, fmt.Errorf("goType: fieldName=%s, %w", fieldSchema.Name, err)
+			}
+		}
+		if goType.ImportPath != "" {
+			importPackages = append(importPackages, goType.ImportPath)
+		}
+		if fieldSchema.Mode == FieldModeRepeated && !overridden {
+			// NOTE(ginokent): REPEATED fields (ARRAY<T>) are loaded into slices. ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L310-L316
+			goType.Name = "[]" + goType.Name
+		}
+
+		var tags []Tag
+		tags, err = g.fieldTags(fieldSchema, tagScopes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fieldTags: fieldName=%s, %w", fieldSchema.Name, err)
+		}
+
+		structData.Fields = append(structData.Fields, &FieldData{
+			Name:       fieldName,
+			Type:       goType.Name,
+			ImportPath: goType.ImportPath,
+			ColumnPath: fieldPath,
+			Schema:     fieldSchema,
+			Tags:       tags,
+			Doc:        fieldDoc(fieldSchema),
+			Comment:    fieldTypeComment(fieldSchema),
+		})
+	}
+
+	return append([]*StructData{structData}, nestedStructs...), importPackages, nil
+}
+
+// fieldDoc returns the lines of the doc comment of fieldSchema, which are the lines of the description and the policy tags.
+// NOTE(ginokent): the policy tags are annotated so that the sensitive columns (e.g. PII) are visible in the code review.
+func fieldDoc(fieldSchema *FieldSchema) (lines []string) {
+	lines = commentLines(fieldSchema.Description)
+	if len(fieldSchema.PolicyTags) > 0 {
+		lines = append(lines, "Policy tags: "+strings.Join(commentLines(strings.Join(fieldSchema.PolicyTags, ", ")), " "))
+	}
+	return lines
+}
+
+// commentLines splits text into the lines of a `//` comment, so that a line break in text can not end the comment.
+// The control characters are escaped by escapeCommentLine, and the trailing spaces of the lines and the leading and trailing empty lines are removed.
+func commentLines(text string) (lines []string) {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	for _, line := range strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(text), "\n") {
+		lines = append(lines, escapeCommentLine(strings.TrimRight(line, " \t")))
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// escapeCommentLine escapes the characters of line that a Go source file can not contain or that break the comment in the editors,
+// which are the control characters other than tab, U+2028, U+2029, BOM and the invalid UTF-8 bytes, as `\x1b` or `\u2028`.
+// NOTE(ginokent): ref. https://go.dev/ref/spec#Source_code_representation
+func escapeCommentLine(line string) (escaped string) {
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, line[i])
+		case r == '\t':
+			b.WriteRune(r)
+		case r < utf8.RuneSelf && unicode.IsControl(r):
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case unicode.IsControl(r) || r == '\u2028' || r == '\u2029' || r == '\ufeff':
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteString(line[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// fieldTypeComment returns the BigQuery type details that the Go type of fieldSchema can not express.
+func fieldTypeComment(fieldSchema *FieldSchema) (comment string) {
+	switch fieldSchema.Type {
+	case bigquery.BigNumericFieldType:
+		if fieldSchema.Precision > 0 {
+			return "BIGNUMERIC(" + strconv.FormatInt(fieldSchema.Precision, 10) + ", " + strconv.FormatInt(fieldSchema.Scale, 10) + ")"
+		}
+		// NOTE(ginokent): ref. https://cloud.google.com/bigquery/docs/reference/standard-sql/data-types#decimal_types
+		return "BIGNUMERIC: precision 76.76 (the 77th digit is partial), scale 38"
+	case bigquery.RangeFieldType:
+		if fieldSchema.RangeElementType != "" {
+			return "RANGE<" + string(fieldSchema.RangeElementType) + ">"
+		}
+		return "RANGE"
+	case bigquery.IntervalFieldType:
+		// NOTE(ginokent): the struct loader of bigquery.RowIterator has no setter for INTERVAL. ref. https://github.com/googleapis/google-cloud-go/blob/bigquery/v1.85.0/bigquery/value.go#L299-L449
+		return "INTERVAL: bigquery.RowIterator can not load it into a struct field"
+	default:
+		return ""
+	}
+}
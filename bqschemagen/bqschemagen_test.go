@@ -0,0 +1,1072 @@
+package bqschemagen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+const (
+	// all
+	testEmptyString                = ""
+	GOOGLE_APPLICATION_CREDENTIALS = "GOOGLE_APPLICATION_CREDENTIALS"
+
+	// generateTableSchemaCode, getAllTables
+	testPublicDataProjectID         = "bigquery-public-data"
+	testSupportedDatasetID          = "hacker_news"
+	testNotSupportedDatasetID       = "samples"
+	testProjectNotFound             = "projectnotfound"
+	testDatasetNotFound             = "datasetnotfound"
+	testSubStrFieldTypeNotSupported = "bigquery.FieldType not supported."
+
+	// getAllTables
+	testGoogleApplicationCredentials = "../test/serviceaccountnotfound@projectnotfound.iam.gserviceaccount.com.json"
+
+	// readSchemaManifest
+	testErrNoSuchFileOrDirectoryPath = "/no/such/file/or/directory"
+
+	// capitalizeInitial
+	testNotCapitalized = "a"
+	testCapitalized    = "A"
+
+	// goType
+	testNotSupportedFieldType = "notSupportedFieldType"
+)
+
+func Test_Generator_Generate(t *testing.T) {
+	t.Run("正常系_NewMemorySource", func(t *testing.T) {
+		source := NewMemorySource(
+			&TableSchema{
+				TableID:     "users",
+				FullID:      tableFullID(testPublicDataProjectID, testSupportedDatasetID, "users"),
+				Description: "users table",
+				Fields: []*FieldSchema{
+					{Name: "user_id", Type: bigquery.IntegerFieldType, Mode: FieldModeRequired},
+					{Name: "created_at", Type: bigquery.TimestampFieldType},
+				},
+			},
+			&TableSchema{
+				TableID: "events",
+				Fields:  []*FieldSchema{{Name: "amount", Type: bigquery.NumericFieldType}},
+			},
+		)
+
+		generatedCode, err := newTestGenerator(t, Options{}).Generate(context.Background(), source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"// Users is BigQuery Table `bigquery-public-data:hacker_news.users` schema struct.\n// Description: users table\n",
+			"\tUserID    int64     `bigquery:\"user_id\"`\n",
+			"\tAmount *big.Rat `bigquery:\"amount\"`\n",
+		} {
+			if !strings.Contains(string(generatedCode), want) {
+				t.Error("Generate: want=`" + want + "` current=`" + string(generatedCode) + "`")
+			}
+		}
+		if strings.Index(string(generatedCode), "type Events struct") > strings.Index(string(generatedCode), "type Users struct") {
+			t.Error("Generate: tables are not sorted: " + string(generatedCode))
+		}
+	})
+
+	t.Run("正常系_adversarial_description", func(t *testing.T) {
+		const (
+			// 正しい出力
+			testComment = "// Users is BigQuery Table `project:dataset.users` schema struct.\n" +
+				"// Description: users\n" +
+				"// }\n" +
+				"//\n" +
+				"// func init() { panic(\"injected\") }\n" +
+				"// /* \\x00\\x1b[31m\\u2028\\xff */ //go:generate rm -rf /\n" +
+				"// var _ = `\n" +
+				"type Users struct {\n"
+		)
+		source := NewMemorySource(&TableSchema{
+			TableID:     "users",
+			FullID:      "project:dataset.users",
+			Description: "users\r\n}\n\nfunc init() { panic(\"injected\") }\r/* \x00\x1b[31m\u2028\xff */ //go:generate rm -rf /\nvar _ = `",
+			Fields: []*FieldSchema{
+				{Name: "id", Type: bigquery.IntegerFieldType, Description: "*/\npackage main\n\x7f"},
+			},
+		})
+
+		generatedCode, err := newTestGenerator(t, Options{}).Generate(context.Background(), source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			testComment,
+			"\t// */\n\t// package main\n\t// \\x7f\n\tID int64 `bigquery:\"id\"`\n",
+		} {
+			if !strings.Contains(string(generatedCode), want) {
+				t.Error("Generate: want=`" + want + "` current=`" + string(generatedCode) + "`")
+			}
+		}
+		if strings.Contains(string(generatedCode), "\nfunc init()") || strings.Contains(string(generatedCode), "\n//go:generate rm") {
+			t.Error("Generate: code is injected: " + string(generatedCode))
+		}
+	})
+
+	t.Run("正常系_skip_not_supported_table", func(t *testing.T) {
+		source := NewMemorySource(
+			&TableSchema{TableID: "ok", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}},
+			&TableSchema{TableID: "ng", Fields: []*FieldSchema{{Name: "id", Type: testNotSupportedFieldType}}},
+		)
+
+		generatedCode, err := newTestGenerator(t, Options{}).Generate(context.Background(), source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(generatedCode), "type Ok struct") || strings.Contains(string(generatedCode), "type Ng struct") {
+			t.Error("Generate: " + string(generatedCode))
+		}
+	})
+
+	t.Run("正常系_Logger_DebugWriter", func(t *testing.T) {
+		var defaultLog bytes.Buffer
+		log.SetOutput(&defaultLog)
+		defer log.SetOutput(os.Stderr)
+
+		source := NewMemorySource(
+			&TableSchema{TableID: "ok", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}},
+			&TableSchema{TableID: "ng", Fields: []*FieldSchema{{Name: "id", Type: testNotSupportedFieldType}}},
+		)
+
+		var logs, debug bytes.Buffer
+		g := newTestGenerator(t, Options{Logger: log.New(&logs, "", 0), Debug: true, DebugWriter: &debug})
+		if _, err := g.Generate(context.Background(), source); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"WARN: generateTableSchemaCode: ", "INFO: generated 1 tables"} {
+			if !strings.Contains(logs.String(), want) {
+				t.Error("Generate: want=`" + want + "` current=`" + logs.String() + "`")
+			}
+		}
+		if !strings.Contains(debug.String(), ">>>> DEBUG >>>>>>>>>>>>>>>>") || !strings.Contains(debug.String(), "type Ok struct") {
+			t.Error("Generate: debug=`" + debug.String() + "`")
+		}
+		if defaultLog.Len() != 0 {
+			t.Error("Generate: log.Default() is used: " + defaultLog.String())
+		}
+	})
+
+	t.Run("異常系_column_override_table_not_found", func(t *testing.T) {
+		g := newTestGenerator(t, Options{ColumnNames: map[string]string{"notfound.id": "ID"}})
+
+		source := NewMemorySource(&TableSchema{TableID: "ok", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}})
+		if _, err := g.Generate(context.Background(), source); !errors.Is(err, ErrColumnOverrideNotFound) {
+			t.Error(err)
+		}
+	})
+}
+
+func Test_Generator_GenerateDatasets(t *testing.T) {
+	users := func(fullID string) *TableSchema {
+		return &TableSchema{TableID: "users", FullID: fullID, Fields: []*FieldSchema{{Name: "user_id", Type: bigquery.IntegerFieldType}}}
+	}
+
+	t.Run("正常系_namespace_DatasetID", func(t *testing.T) {
+		generatedCode, err := newTestGenerator(t, Options{PackageName: "warehouse"}).GenerateDatasets(context.Background(), []Dataset{
+			{ProjectID: "proj-a", DatasetID: "sales", Source: NewMemorySource(users("proj-a:sales.users"))},
+			{ProjectID: "proj-a", DatasetID: "crm_raw", Source: NewMemorySource(users("proj-a:crm_raw.users"))},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"\npackage warehouse\n",
+			"// SalesUsers is BigQuery Table `proj-a:sales.users` schema struct.\n",
+			"type SalesUsers struct",
+			"// CrmRawUsers is BigQuery Table `proj-a:crm_raw.users` schema struct.\n",
+			"type CrmRawUsers struct",
+		} {
+			if !strings.Contains(string(generatedCode), want) {
+				t.Error("GenerateDatasets: want=`" + want + "` current=`" + string(generatedCode) + "`")
+			}
+		}
+	})
+
+	t.Run("正常系_namespace_ProjectID_DatasetID", func(t *testing.T) {
+		generatedCode, err := newTestGenerator(t, Options{}).GenerateDatasets(context.Background(), []Dataset{
+			{ProjectID: "proj-a", DatasetID: "sales", Source: NewMemorySource(users("proj-a:sales.users"))},
+			{ProjectID: "proj-b", DatasetID: "sales", Source: NewMemorySource(users("proj-b:sales.users"))},
+			{ProjectID: "proj-b", DatasetID: "events", Source: NewMemorySource(users("proj-b:events.users"))},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"type ProjASalesUsers struct", "type ProjBSalesUsers struct", "type EventsUsers struct"} {
+			if !strings.Contains(string(generatedCode), want) {
+				t.Error("GenerateDatasets: want=`" + want + "` current=`" + string(generatedCode) + "`")
+			}
+		}
+	})
+
+	t.Run("異常系_duplicate_dataset", func(t *testing.T) {
+		if _, err := newTestGenerator(t, Options{}).GenerateDatasets(context.Background(), []Dataset{
+			{ProjectID: "proj-a", DatasetID: "sales", Source: NewMemorySource(users(""))},
+			{ProjectID: "proj-a", DatasetID: "sales", Source: NewMemorySource(users(""))},
+		}); err == nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("異常系_column_override_table_not_found", func(t *testing.T) {
+		g := newTestGenerator(t, Options{ColumnNames: map[string]string{"notfound.user_id": "ID"}})
+		if _, err := g.GenerateDatasets(context.Background(), []Dataset{
+			{ProjectID: "proj-a", DatasetID: "sales", Source: NewMemorySource(users(""))},
+		}); !errors.Is(err, ErrColumnOverrideNotFound) {
+			t.Error(err)
+		}
+	})
+}
+
+func Test_Generator_GeneratePackages(t *testing.T) {
+	table := func(tableID, column string) *TableSchema {
+		return &TableSchema{TableID: tableID, Fields: []*FieldSchema{{Name: column, Type: bigquery.IntegerFieldType}}}
+	}
+	newDatasets := func() []Dataset {
+		return []Dataset{
+			{ProjectID: "proj-a", DatasetID: "sales", Source: &countingSource{SchemaSource: NewMemorySource(table("users", "user_id"), table("orders", "order_id"))}},
+			{ProjectID: "proj-a", DatasetID: "events", Source: &countingSource{SchemaSource: NewMemorySource(table("users", "user_id"), table("clicks", "click_id"))}},
+		}
+	}
+
+	t.Run("正常系_column_overrides_of_other_dataset", func(t *testing.T) {
+		datasets := newDatasets()
+		g := newTestGenerator(t, Options{ColumnNames: map[string]string{"orders.order_id": "ID", "users.user_id": "UID"}})
+		generatedCodes, err := g.GeneratePackages(context.Background(), datasets, []string{"sales", "events"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, wants := range [][]string{
+			{"\npackage sales\n", "type Users struct", "\tUID int64 `bigquery:\"user_id\"`\n", "\tID int64 `bigquery:\"order_id\"`\n"},
+			{"\npackage events\n", "type Users struct", "\tUID int64 `bigquery:\"user_id\"`\n", "type Clicks struct"},
+		} {
+			for _, want := range wants {
+				if !strings.Contains(string(generatedCodes[i]), want) {
+					t.Error("GeneratePackages: want=`" + want + "` current=`" + string(generatedCodes[i]) + "`")
+				}
+			}
+		}
+		for _, dataset := range datasets {
+			if calls := dataset.Source.(*countingSource).tableIDsCalls; calls != 1 {
+				t.Errorf("GeneratePackages: datasetID=%s, TableIDs is called %d times", dataset.DatasetID, calls)
+			}
+		}
+	})
+
+	t.Run("正常系_GeneratePackageFiles", func(t *testing.T) {
+		packageFiles, err := newTestGenerator(t, Options{}).GeneratePackageFiles(context.Background(), newDatasets(), []string{"sales", "events"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(packageFiles) != 2 || len(packageFiles[0]) != 2 || !strings.Contains(string(packageFiles[1]["clicks.generated.go"]), "\npackage events\n") {
+			t.Errorf("GeneratePackageFiles: files=%v", packageFiles)
+		}
+	})
+
+	t.Run("異常系_column_override_table_not_found", func(t *testing.T) {
+		g := newTestGenerator(t, Options{ColumnNames: map[string]string{"notfound.user_id": "ID"}})
+		if _, err := g.GeneratePackages(context.Background(), newDatasets(), []string{"sales", "events"}); !errors.Is(err, ErrColumnOverrideNotFound) {
+			t.Error(err)
+		}
+	})
+
+	t.Run("異常系_packageNames", func(t *testing.T) {
+		for _, packageNames := range [][]string{{"sales"}, {"sales", "1events"}} {
+			if _, err := newTestGenerator(t, Options{}).GeneratePackages(context.Background(), newDatasets(), packageNames); err == nil {
+				t.Errorf("GeneratePackages: packageNames=%v", packageNames)
+			}
+		}
+	})
+}
+
+func Test_Generator_executeTemplate_imports(t *testing.T) {
+	g := newTestGenerator(t, Options{})
+	for name, tt := range map[string]struct {
+		imports []string
+		// 正しい出力
+		want string
+	}{
+		"正常系_import_nothing": {nil, "package bqschema\n\n"},
+		"正常系_import_time":    {[]string{"time"}, "package bqschema\n\nimport \"time\"\n\n"},
+		"正常系_import_math/big_time": {[]string{"math/big", "time"}, `package bqschema
+
+import (
+	"math/big"
+	"time"
+)
+
+`},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			generatedCode, err := g.executeTemplate("file", &FileData{PackageName: "bqschema", Imports: tt.imports})
+			if err != nil {
+				t.Fatal(err)
+			}
+			generatedCode = strings.TrimPrefix(generatedCode, GeneratedCodeComment+"\n\n")
+			if generatedCode != tt.want {
+				var (
+					rr      = strings.NewReplacer("\n", "\\n", "`", "\\`")
+					want    = rr.Replace(tt.want)
+					current = rr.Replace(generatedCode)
+				)
+				t.Error("executeTemplate: want=`" + want + "` current=`" + current + "`")
+			}
+		})
+	}
+}
+
+func Test_generateTableSchemaCode(t *testing.T) {
+	g := newTestGenerator(t, Options{})
+
+	t.Run("正常系_testNotSupportedDatasetID", func(t *testing.T) {
+		table := &TableSchema{
+			TableID: "shakespeare",
+			FullID:  tableFullID(testPublicDataProjectID, testNotSupportedDatasetID, "shakespeare"),
+			Fields: []*FieldSchema{
+				{Name: "word", Type: bigquery.StringFieldType, Mode: FieldModeRequired},
+				{Name: "word_count", Type: bigquery.IntegerFieldType, Mode: FieldModeRequired},
+			},
+		}
+		data, err := g.generateTableSchemaCode(table, "", g.newIdentifierScope("package"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "// Shakespeare is BigQuery Table `bigquery-public-data:samples.shakespeare` schema struct.\n"; !strings.HasPrefix(data.Code, want) {
+			t.Error("generateTableSchemaCode: want=`" + want + "` current=`" + data.Code + "`")
+		}
+	})
+
+	t.Run("異常系_testEmptyString", func(t *testing.T) {
+		var (
+			ngTable = &TableSchema{
+				TableID: testEmptyString,
+				FullID:  tableFullID(testProjectNotFound, testDatasetNotFound, testEmptyString),
+				Fields:  []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}},
+			}
+		)
+		if _, err := g.generateTableSchemaCode(ngTable, "", g.newIdentifierScope("package")); err == nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("異常系_testSubStrFieldTypeNotSupported", func(t *testing.T) {
+		var (
+			ngTable = &TableSchema{
+				TableID: "ng",
+				Fields:  []*FieldSchema{{Name: "id", Type: testNotSupportedFieldType}},
+			}
+		)
+		_, err := g.generateTableSchemaCode(ngTable, "", g.newIdentifierScope("package"))
+		if err == nil || !strings.Contains(err.Error(), testSubStrFieldTypeNotSupported) {
+			t.Error(err)
+		}
+	})
+}
+
+func Test_generateStructs(t *testing.T) {
+	g := newTestGenerator(t, Options{})
+
+	t.Run("正常系_nested_record", func(t *testing.T) {
+		const (
+			// 正しい出力
+			testStructCode = "type Events struct {\n" +
+				"\tName string `bigquery:\"name\"`\n" +
+				"\tPayload *EventsPayload `bigquery:\"payload\"`\n" +
+				"}\n" +
+				"\n" +
+				"// EventsPayload is BigQuery RECORD field `payload` schema struct in Events.\n" +
+				"type EventsPayload struct {\n" +
+				"\tAt time.Time `bigquery:\"at\"`\n" +
+				"\tUser *EventsPayloadUser `bigquery:\"user\"`\n" +
+				"}\n" +
+				"\n" +
+				"// EventsPayloadUser is BigQuery RECORD field `user` schema struct in EventsPayload.\n" +
+				"type EventsPayloadUser struct {\n" +
+				"\tID int64 `bigquery:\"id\"`\n" +
+				"}\n"
+		)
+		var (
+			testSchema = []*FieldSchema{
+				{Name: "name", Type: bigquery.StringFieldType},
+				{Name: "payload", Type: bigquery.RecordFieldType, Fields: []*FieldSchema{
+					{Name: "at", Type: bigquery.TimestampFieldType},
+					{Name: "user", Type: bigquery.RecordFieldType, Fields: []*FieldSchema{
+						{Name: "id", Type: bigquery.IntegerFieldType},
+					}},
+				}},
+			}
+		)
+
+		generatedCode, importPackages, err := generateTestStructCode(g, "Events", "events", testSchema, g.newIdentifierScope("package"))
+		if err != nil {
+			t.Error(err)
+		}
+		if generatedCode != testStructCode {
+			var (
+				rr      = strings.NewReplacer("\n", "\\n", "`", "\\`")
+				want    = rr.Replace(testStructCode)
+				current = rr.Replace(generatedCode)
+			)
+			t.Error("generateStructs: want=`" + want + "` current=`" + current + "`")
+		}
+		if !reflect.DeepEqual(importPackages, []string{"time"}) {
+			t.Error("generateStructs: importPackages=", importPackages)
+		}
+	})
+
+	t.Run("正常系_repeated", func(t *testing.T) {
+		const (
+			// 正しい出力
+			testStructCode = "type Events struct {\n" +
+				"\tTags []string `bigquery:\"tags\"`\n" +
+				"\tItems []EventsItems `bigquery:\"items\"`\n" +
+				"}\n" +
+				"\n" +
+				"// EventsItems is BigQuery RECORD field `items` schema struct in Events.\n" +
+				"type EventsItems struct {\n" +
+				"\tPrices []*big.Rat `bigquery:\"prices\"`\n" +
+				"}\n"
+		)
+		var (
+			testSchema = []*FieldSchema{
+				{Name: "tags", Type: bigquery.StringFieldType, Mode: FieldModeRepeated},
+				{Name: "items", Type: bigquery.RecordFieldType, Mode: FieldModeRepeated, Fields: []*FieldSchema{
+					{Name: "prices", Type: bigquery.NumericFieldType, Mode: FieldModeRepeated},
+				}},
+			}
+		)
+
+		generatedCode, _, err := generateTestStructCode(g, "Events", "events", testSchema, g.newIdentifierScope("package"))
+		if err != nil {
+			t.Error(err)
+		}
+		if generatedCode != testStructCode {
+			var (
+				rr      = strings.NewReplacer("\n", "\\n", "`", "\\`")
+				want    = rr.Replace(testStructCode)
+				current = rr.Replace(generatedCode)
+			)
+			t.Error("generateStructs: want=`" + want + "` current=`" + current + "`")
+		}
+	})
+
+	t.Run("正常系_doc", func(t *testing.T) {
+		const (
+			// 正しい出力
+			testStructCode = "type Users struct {\n" +
+				"\t// user id\n" +
+				"\tUserID int64 `bigquery:\"user_id\"`\n" +
+				"\t// e-mail address.\n" +
+				"\t//\n" +
+				"\t// verified at sign up\n" +
+				"\t// Policy tags: projects/p/locations/l/taxonomies/t/policyTags/pii\n" +
+				"\tEmail string `bigquery:\"email\"`\n" +
+				"\tProfile *UsersProfile `bigquery:\"profile\"`\n" +
+				"}\n" +
+				"\n" +
+				"// UsersProfile is BigQuery RECORD field `profile` schema struct in Users.\n" +
+				"type UsersProfile struct {\n" +
+				"\t// Policy tags: projects/p/locations/l/taxonomies/t/policyTags/pii, projects/p/locations/l/taxonomies/t/policyTags/age\n" +
+				"\tAge int64 `bigquery:\"age\"`\n" +
+				"}\n"
+		)
+		var (
+			testSchema = []*FieldSchema{
+				{Name: "user_id", Type: bigquery.IntegerFieldType, Description: "user id"},
+				{Name: "email", Type: bigquery.StringFieldType, Description: "e-mail address.  \r\n\r\nverified at sign up\n", PolicyTags: []string{"projects/p/locations/l/taxonomies/t/policyTags/pii"}},
+				{Name: "profile", Type: bigquery.RecordFieldType, Fields: []*FieldSchema{
+					{Name: "age", Type: bigquery.IntegerFieldType, PolicyTags: []string{"projects/p/locations/l/taxonomies/t/policyTags/pii", "projects/p/locations/l/taxonomies/t/policyTags/age"}},
+				}},
+			}
+		)
+
+		generatedCode, _, err := generateTestStructCode(g, "Users", "users", testSchema, g.newIdentifierScope("package"))
+		if err != nil {
+			t.Error(err)
+		}
+		if generatedCode != testStructCode {
+			var (
+				rr      = strings.NewReplacer("\n", "\\n", "`", "\\`")
+				want    = rr.Replace(testStructCode)
+				current = rr.Replace(generatedCode)
+			)
+			t.Error("generateStructs: want=`" + want + "` current=`" + current + "`")
+		}
+	})
+
+	t.Run("異常系_empty_record", func(t *testing.T) {
+		var (
+			testSchema = []*FieldSchema{
+				{Name: "payload", Type: bigquery.RecordFieldType},
+			}
+		)
+
+		if _, _, err := generateTestStructCode(g, "Events", "events", testSchema, g.newIdentifierScope("package")); err == nil {
+			t.Error(err)
+		}
+	})
+}
+
+func Test_generateStructs_nullRecord(t *testing.T) {
+	const (
+		// 正しい出力
+		testStructCode = "type Events struct {\n" +
+			"\tID int64 `bigquery:\"id\"`\n" +
+			"\tPayload *EventsPayload `bigquery:\"payload\"`\n" +
+			"}\n" +
+			"\n" +
+			"// EventsPayload is BigQuery RECORD field `payload` schema struct in Events.\n" +
+			"type EventsPayload struct {\n" +
+			"\tName string `bigquery:\"name\"`\n" +
+			"}\n"
+	)
+	var (
+		testSchema = []*FieldSchema{
+			{Name: "id", Type: bigquery.IntegerFieldType, Mode: FieldModeRequired},
+			{Name: "payload", Type: bigquery.RecordFieldType, Fields: []*FieldSchema{
+				{Name: "name", Type: bigquery.StringFieldType},
+			}},
+		}
+	)
+
+	// NOTE(ginokent): the structs of testStructCode, which are loaded by RowIterator.Next below.
+	type EventsPayload struct {
+		Name string `bigquery:"name"`
+	}
+	type Events struct {
+		ID      int64          `bigquery:"id"`
+		Payload *EventsPayload `bigquery:"payload"`
+	}
+
+	for _, mode := range []NullableMode{NullableModeValue, NullableModeNull, NullableModePointer} {
+		g := newTestGenerator(t, Options{NullableMode: mode})
+		generatedCode, _, err := generateTestStructCode(g, "Events", "events", testSchema, g.newIdentifierScope("package"))
+		if err != nil {
+			t.Error(err)
+		}
+		if mode == NullableModeValue && generatedCode != testStructCode {
+			t.Error("generateStructs: NullableMode=" + string(mode) + " want=`" + testStructCode + "` current=`" + generatedCode + "`")
+		}
+		if !strings.Contains(generatedCode, "\tPayload *EventsPayload `bigquery:\"payload\"`\n") {
+			t.Error("generateStructs: NullableMode=" + string(mode) + " current=`" + generatedCode + "`")
+		}
+	}
+
+	it := readTestRows(t,
+		`{"schema": {"fields": [{"name": "id", "type": "INTEGER", "mode": "REQUIRED"}, {"name": "payload", "type": "RECORD", "fields": [{"name": "name", "type": "STRING"}]}]}}`,
+		`{"totalRows": "2", "rows": [{"f": [{"v": "1"}, {"v": null}]}, {"f": [{"v": "2"}, {"v": {"f": [{"v": "a"}]}}]}]}`,
+	)
+	var rows []Events
+	for {
+		var row Events
+		err := it.Next(&row)
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+	if want := []Events{{ID: 1}, {ID: 2, Payload: &EventsPayload{Name: "a"}}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("RowIterator.Next: want=%+v current=%+v", want, rows)
+	}
+}
+
+func Test_fieldTypeComment(t *testing.T) {
+	for name, tt := range map[string]struct {
+		fieldSchema *FieldSchema
+		want        string
+	}{
+		"正常系_BIGNUMERIC":           {&FieldSchema{Type: bigquery.BigNumericFieldType}, "BIGNUMERIC: precision 76.76 (the 77th digit is partial), scale 38"},
+		"正常系_BIGNUMERIC_precision": {&FieldSchema{Type: bigquery.BigNumericFieldType, Precision: 50, Scale: 10}, "BIGNUMERIC(50, 10)"},
+		"正常系_RANGE_DATE":           {&FieldSchema{Type: bigquery.RangeFieldType, RangeElementType: bigquery.DateFieldType}, "RANGE<DATE>"},
+		"正常系_INTERVAL":             {&FieldSchema{Type: bigquery.IntervalFieldType}, "INTERVAL: bigquery.RowIterator can not load it into a struct field"},
+		"正常系_STRING":               {&FieldSchema{Type: bigquery.StringFieldType}, ""},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			if comment := fieldTypeComment(tt.fieldSchema); comment != tt.want {
+				t.Error("fieldTypeComment: want=" + tt.want + " current=" + comment)
+			}
+		})
+	}
+}
+
+func Test_commentLines(t *testing.T) {
+	for name, tt := range map[string]struct {
+		text string
+		want []string
+	}{
+		"正常系_single_line": {"user id", []string{"user id"}},
+		"正常系_multi_line":  {"\nfirst  \r\n\r\nsecond\rthird\n\n", []string{"first", "", "second", "third"}},
+		"正常系_control":     {"a\x00b\x1b[0m\tc\u0085\u2028\ufeff\xffd", []string{`a\x00b\x1b[0m` + "\t" + `c\u0085\u2028\ufeff\xffd`}},
+		"正常系_empty":       {"", nil},
+		"正常系_blank":       {" \n\t\n", nil},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			if lines := commentLines(tt.text); !reflect.DeepEqual(lines, tt.want) {
+				t.Errorf("commentLines: want=%q current=%q", tt.want, lines)
+			}
+		})
+	}
+}
+
+func Test_capitalizeInitial(t *testing.T) {
+	t.Run("正常系_testEmptyString", func(t *testing.T) {
+		if capitalizeInitial(testEmptyString) != testEmptyString {
+			t.Error()
+		}
+	})
+
+	t.Run("正常系_testCapitalized", func(t *testing.T) {
+		if capitalizeInitial(testNotCapitalized) != testCapitalized {
+			t.Error()
+		}
+	})
+}
+
+func Test_Generator_goType(t *testing.T) {
+	var (
+		supportedBigqueryFieldTypes = map[bigquery.FieldType]string{
+			bigquery.StringFieldType:    reflect.String.String(),
+			bigquery.BytesFieldType:     typeOfByteSlice.String(),
+			bigquery.IntegerFieldType:   reflect.Int64.String(),
+			bigquery.FloatFieldType:     reflect.Float64.String(),
+			bigquery.BooleanFieldType:   reflect.Bool.String(),
+			bigquery.TimestampFieldType: typeOfGoTime.String(),
+			// NOTE(ginokent): bigquery.RecordFieldType is tested in Test_generateStructs
+			bigquery.DateFieldType:       typeOfDate.String(),
+			bigquery.TimeFieldType:       typeOfTime.String(),
+			bigquery.DateTimeFieldType:   typeOfDateTime.String(),
+			bigquery.NumericFieldType:    typeOfRat.String(),
+			bigquery.GeographyFieldType:  reflect.String.String(),
+			bigquery.BigNumericFieldType: typeOfRat.String(),
+			bigquery.RangeFieldType:      typeOfRangeValue.String(),
+			bigquery.JSONFieldType:       reflect.String.String(),
+			bigquery.IntervalFieldType:   typeOfIntervalValue.String(),
+		}
+
+		unsupportedBigqueryFieldTypes = map[bigquery.FieldType]string{
+			bigquery.RecordFieldType:                      testEmptyString,
+			bigquery.FieldType(testNotSupportedFieldType): testEmptyString,
+		}
+	)
+
+	t.Run("正常系_supportedBigqueryFieldTypes", func(t *testing.T) {
+		g := newTestGenerator(t, Options{})
+		for bigqueryFieldType, typeOf := range supportedBigqueryFieldTypes {
+			goType, err := g.goType(bigqueryFieldType, false)
+			if err != nil {
+				t.Error(err)
+			}
+			if goType.Name != typeOf {
+				t.Error()
+			}
+		}
+	})
+
+	t.Run("正常系_importPath", func(t *testing.T) {
+		g := newTestGenerator(t, Options{})
+		for bigqueryFieldType, importPath := range map[bigquery.FieldType]string{
+			bigquery.BytesFieldType:     "",
+			bigquery.DateFieldType:      "cloud.google.com/go/civil",
+			bigquery.TimestampFieldType: "time",
+			bigquery.NumericFieldType:   "math/big",
+			bigquery.RangeFieldType:     "cloud.google.com/go/bigquery",
+		} {
+			goType, err := g.goType(bigqueryFieldType, false)
+			if err != nil {
+				t.Error(err)
+			}
+			if goType.ImportPath != importPath {
+				t.Error("goType: want=" + importPath + " current=" + goType.ImportPath)
+			}
+		}
+	})
+
+	t.Run("異常系_unsupportedBigqueryFieldTypes", func(t *testing.T) {
+		g := newTestGenerator(t, Options{})
+		for bigqueryFieldType, typeOf := range unsupportedBigqueryFieldTypes {
+			goType, err := g.goType(bigqueryFieldType, false)
+			if err == nil {
+				t.Error(err)
+			}
+			if goType.Name != typeOf {
+				t.Error()
+			}
+		}
+	})
+	t.Run("正常系_JSONTypeRawMessage", func(t *testing.T) {
+		g := newTestGenerator(t, Options{JSONType: JSONTypeRawMessage})
+
+		goType, err := g.goType(bigquery.JSONFieldType, false)
+		if err != nil {
+			t.Error(err)
+		}
+		if goType.Name != "json.RawMessage" || goType.ImportPath != "encoding/json" {
+			t.Error("goType: goType=" + goType.Name + " pkg=" + goType.ImportPath)
+		}
+	})
+
+	t.Run("正常系_INTERVAL", func(t *testing.T) {
+		for _, nullableMode := range []NullableMode{NullableModeValue, NullableModeNull, NullableModePointer} {
+			g := newTestGenerator(t, Options{NullableMode: nullableMode})
+
+			goType, err := g.goType(bigquery.IntervalFieldType, true)
+			if err != nil {
+				t.Error(err)
+			}
+			if goType.Name != "*bigquery.IntervalValue" || goType.ImportPath != "cloud.google.com/go/bigquery" {
+				t.Error("goType: nullableMode=" + string(nullableMode) + " goType=" + goType.Name + " pkg=" + goType.ImportPath)
+			}
+		}
+	})
+
+	t.Run("正常系_INTERVAL_TypeMap", func(t *testing.T) {
+		g := newTestGenerator(t, Options{TypeMap: map[bigquery.FieldType]GoType{bigquery.IntervalFieldType: {Name: "string"}}})
+
+		goType, err := g.goType(bigquery.IntervalFieldType, false)
+		if err != nil {
+			t.Error(err)
+		}
+		if goType.Name != "string" {
+			t.Error("goType: want=string current=" + goType.Name)
+		}
+	})
+
+	t.Run("正常系_NullableModeNull", func(t *testing.T) {
+		g := newTestGenerator(t, Options{NullableMode: NullableModeNull})
+
+		for bigqueryFieldType, typeOf := range map[bigquery.FieldType]string{
+			bigquery.IntegerFieldType:   "bigquery.NullInt64",
+			bigquery.TimestampFieldType: "bigquery.NullTimestamp",
+			bigquery.NumericFieldType:   typeOfRat.String(),
+			bigquery.BytesFieldType:     typeOfByteSlice.String(),
+			bigquery.JSONFieldType:      "bigquery.NullJSON",
+			bigquery.RangeFieldType:     typeOfRangeValue.String(),
+		} {
+			goType, err := g.goType(bigqueryFieldType, true)
+			if err != nil {
+				t.Error(err)
+			}
+			if goType.Name != typeOf {
+				t.Error("goType: want=" + typeOf + " current=" + goType.Name)
+			}
+		}
+
+		goType, err := g.goType(bigquery.IntegerFieldType, false)
+		if err != nil {
+			t.Error(err)
+		}
+		if goType.Name != reflect.Int64.String() {
+			t.Error("goType: want=" + reflect.Int64.String() + " current=" + goType.Name)
+		}
+	})
+
+	t.Run("正常系_NullableModePointer", func(t *testing.T) {
+		g := newTestGenerator(t, Options{NullableMode: NullableModePointer})
+
+		for bigqueryFieldType, typeOf := range map[bigquery.FieldType]string{
+			bigquery.IntegerFieldType:   "*int64",
+			bigquery.TimestampFieldType: "*time.Time",
+			bigquery.NumericFieldType:   typeOfRat.String(),
+			bigquery.BytesFieldType:     typeOfByteSlice.String(),
+		} {
+			goType, err := g.goType(bigqueryFieldType, true)
+			if err != nil {
+				t.Error(err)
+			}
+			if goType.Name != typeOf {
+				t.Error("goType: want=" + typeOf + " current=" + goType.Name)
+			}
+		}
+	})
+}
+
+func Test_ParseTypeMap(t *testing.T) {
+	t.Run("正常系", func(t *testing.T) {
+		typeMap, err := ParseTypeMap("NUMERIC=github.com/shopspring/decimal.Decimal, date=example.com/dates.Date,GEOGRAPHY=*github.com/org/geo/v2.Geometry,INT64=int")
+		if err != nil {
+			t.Error(err)
+		}
+		want := map[bigquery.FieldType]GoType{
+			bigquery.NumericFieldType:   {Name: "decimal.Decimal", ImportPath: "github.com/shopspring/decimal"},
+			bigquery.DateFieldType:      {Name: "dates.Date", ImportPath: "example.com/dates"},
+			bigquery.GeographyFieldType: {Name: "*geo.Geometry", ImportPath: "github.com/org/geo/v2"},
+			bigquery.IntegerFieldType:   {Name: "int", ImportPath: ""},
+		}
+		if !reflect.DeepEqual(typeMap, want) {
+			t.Errorf("ParseTypeMap: want=%v current=%v", want, typeMap)
+		}
+	})
+
+	t.Run("正常系_testEmptyString", func(t *testing.T) {
+		typeMap, err := ParseTypeMap(testEmptyString)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(typeMap) != 0 {
+			t.Errorf("ParseTypeMap: typeMap=%v", typeMap)
+		}
+	})
+
+	for name, typeMapCSV := range map[string]string{
+		"異常系_no_equal":          "NUMERIC",
+		"異常系_unknown_type":      testNotSupportedFieldType + "=string",
+		"異常系_record":            "RECORD=example.com/x.T",
+		"異常系_duplicate":         "INTEGER=int,INT64=int32",
+		"異常系_missing_type_name": "NUMERIC=github.com/shopspring/decimal",
+	} {
+		typeMapCSV := typeMapCSV
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseTypeMap(typeMapCSV); err == nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_ParseGoType(t *testing.T) {
+	for qualified, want := range map[string]GoType{
+		"time.Time":                     {Name: "time.Time", ImportPath: "time"},
+		"string":                        {Name: "string", ImportPath: ""},
+		"[]*example.com/go-money.Cents": {Name: "[]*money.Cents", ImportPath: "example.com/go-money"},
+		"gopkg.in/guregu/null.v4.Int":   {Name: "null.Int", ImportPath: "gopkg.in/guregu/null.v4"},
+	} {
+		qualified, want := qualified, want
+		t.Run("正常系_"+qualified, func(t *testing.T) {
+			goType, err := ParseGoType(qualified)
+			if err != nil {
+				t.Error(err)
+			}
+			if goType != want {
+				t.Error("ParseGoType: goType=" + goType.Name + " pkg=" + goType.ImportPath)
+			}
+		})
+	}
+}
+
+func Test_Generator_goType_TypeMap(t *testing.T) {
+	typeMap := map[bigquery.FieldType]GoType{
+		bigquery.NumericFieldType:   {Name: "decimal.Decimal", ImportPath: "github.com/shopspring/decimal"},
+		bigquery.TimestampFieldType: {Name: "mypkg.T", ImportPath: "github.com/org/mypkg"},
+	}
+
+	for _, mode := range []NullableMode{NullableModeValue, NullableModeNull} {
+		g := newTestGenerator(t, Options{TypeMap: typeMap, NullableMode: mode})
+		goType, err := g.goType(bigquery.TimestampFieldType, true)
+		if err != nil {
+			t.Error(err)
+		}
+		if goType.Name != "mypkg.T" || goType.ImportPath != "github.com/org/mypkg" {
+			t.Error("goType: NullableMode=" + string(mode) + " goType=" + goType.Name + " pkg=" + goType.ImportPath)
+		}
+	}
+
+	g := newTestGenerator(t, Options{TypeMap: typeMap, NullableMode: NullableModePointer})
+	goType, err := g.goType(bigquery.NumericFieldType, true)
+	if err != nil {
+		t.Error(err)
+	}
+	if goType.Name != "*decimal.Decimal" || goType.ImportPath != "github.com/shopspring/decimal" {
+		t.Error("goType: goType=" + goType.Name + " pkg=" + goType.ImportPath)
+	}
+}
+
+func Test_generateStructs_columnOverrides(t *testing.T) {
+	opts := Options{
+		ColumnTypes: map[string]GoType{
+			"orders.amount":       {Name: "money.Cents", ImportPath: "github.com/org/money"},
+			"orders.buyer.tags":   {Name: "[]Tag", ImportPath: ""},
+			"orders.buyer.raw_id": {Name: "UserID", ImportPath: ""},
+		},
+		ColumnNames: map[string]string{
+			"orders.time_ts":      "CreatedAt",
+			"orders.buyer":        "Customer",
+			"orders.buyer.raw_id": "ID",
+		},
+	}
+
+	const (
+		// 正しい出力
+		testStructCode = "type Orders struct {\n" +
+			"\tAmount money.Cents `bigquery:\"amount\"`\n" +
+			"\tCreatedAt time.Time `bigquery:\"time_ts\"`\n" +
+			"\tCustomer *OrdersCustomer `bigquery:\"buyer\"`\n" +
+			"}\n" +
+			"\n" +
+			"// OrdersCustomer is BigQuery RECORD field `buyer` schema struct in Orders.\n" +
+			"type OrdersCustomer struct {\n" +
+			"\tID UserID `bigquery:\"raw_id\"`\n" +
+			"\tTags []Tag `bigquery:\"tags\"`\n" +
+			"}\n"
+	)
+	var (
+		testSchema = []*FieldSchema{
+			{Name: "amount", Type: bigquery.IntegerFieldType},
+			{Name: "time_ts", Type: bigquery.TimestampFieldType},
+			{Name: "buyer", Type: bigquery.RecordFieldType, Fields: []*FieldSchema{
+				{Name: "raw_id", Type: bigquery.StringFieldType},
+				{Name: "tags", Type: bigquery.StringFieldType, Mode: FieldModeRepeated},
+			}},
+		}
+	)
+
+	t.Run("正常系", func(t *testing.T) {
+		g := newTestGenerator(t, opts)
+		generatedCode, importPackages, err := generateTestStructCode(g, "Orders", "orders", testSchema, g.newIdentifierScope("package"))
+		if err != nil {
+			t.Error(err)
+		}
+		if generatedCode != testStructCode {
+			var (
+				rr      = strings.NewReplacer("\n", "\\n", "`", "\\`")
+				want    = rr.Replace(testStructCode)
+				current = rr.Replace(generatedCode)
+			)
+			t.Error("generateStructs: want=`" + want + "` current=`" + current + "`")
+		}
+		if !reflect.DeepEqual(importPackages, []string{"github.com/org/money", "time"}) {
+			t.Error("generateStructs: importPackages=", importPackages)
+		}
+	})
+}
+
+func Test_ParseColumnNameMap(t *testing.T) {
+	t.Run("正常系", func(t *testing.T) {
+		names, err := ParseColumnNameMap("stories.time_ts=CreatedAt, events.payload.user_id=UserID, example.com:proj:sales.users.email=Mail")
+		if err != nil {
+			t.Error(err)
+		}
+		want := map[string]string{"stories.time_ts": "CreatedAt", "events.payload.user_id": "UserID", "example.com:proj:sales.users.email": "Mail"}
+		if !reflect.DeepEqual(names, want) {
+			t.Errorf("ParseColumnNameMap: want=%v current=%v", want, names)
+		}
+	})
+
+	for name, columnNameCSV := range map[string]string{
+		"異常系_no_table":     "time_ts=CreatedAt",
+		"異常系_unexported":   "stories.time_ts=createdAt",
+		"異常系_invalid_name": "stories.time_ts=Created-At",
+		"異常系_empty_column": "stories..time_ts=CreatedAt",
+		"異常系_duplicate":    "stories.id=ID,stories.id=StoryID",
+		"異常系_no_project":   ":sales.users.email=Mail",
+		"異常系_no_dataset":   "proj:users.email=Mail",
+	} {
+		columnNameCSV := columnNameCSV
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseColumnNameMap(columnNameCSV); err == nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_ParseColumnTypeMap(t *testing.T) {
+	columnTypes, err := ParseColumnTypeMap("orders.amount=github.com/org/money.Cents,users.id=UserID")
+	if err != nil {
+		t.Error(err)
+	}
+	want := map[string]GoType{
+		"orders.amount": {Name: "money.Cents", ImportPath: "github.com/org/money"},
+		"users.id":      {Name: "UserID", ImportPath: ""},
+	}
+	if !reflect.DeepEqual(columnTypes, want) {
+		t.Errorf("ParseColumnTypeMap: want=%v current=%v", want, columnTypes)
+	}
+}
+
+func Test_New(t *testing.T) {
+	t.Run("正常系_zero_Options", func(t *testing.T) {
+		g, err := New(Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.opts.NamingMode != NamingModeCamel || g.opts.Collision != CollisionSuffix || g.opts.NullableMode != NullableModeValue || g.opts.JSONType != JSONTypeString || g.opts.PackageName != "bqschema" || !g.initialisms["ID"] {
+			t.Errorf("New: opts=%#v", g.opts)
+		}
+	})
+
+	for name, opts := range map[string]Options{
+		"異常系_NamingMode":             {NamingMode: "snake"},
+		"異常系_Collision":              {Collision: "ignore"},
+		"異常系_NullableMode":           {NullableMode: "optional"},
+		"異常系_JSONType":               {JSONType: "map"},
+		"異常系_TypeMap_RECORD":         {TypeMap: map[bigquery.FieldType]GoType{bigquery.RecordFieldType: {Name: "T"}}},
+		"異常系_TypeMap_unknown":        {TypeMap: map[bigquery.FieldType]GoType{testNotSupportedFieldType: {Name: "T"}}},
+		"異常系_TypeMap_empty":          {TypeMap: map[bigquery.FieldType]GoType{bigquery.StringFieldType: {}}},
+		"異常系_ColumnTypes_no_table":   {ColumnTypes: map[string]GoType{"id": {Name: "T"}}},
+		"異常系_ColumnNames_unexported": {ColumnNames: map[string]string{"users.id": "id"}},
+		"異常系_PackageName":            {PackageName: "bq-schema"},
+	} {
+		opts := opts
+		t.Run(name, func(t *testing.T) {
+			if _, err := New(opts); err == nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// generateTestStructCode returns the code of the structs of fields rendered by the "structs" template, and the import paths they need.
+func generateTestStructCode(g *Generator, structName string, columnPath string, fields []*FieldSchema, typeScope *identifierScope) (generatedCode string, importPackages []string, err error) {
+	structs, importPackages, err := g.generateStructs(structName, columnPath, fields, typeScope)
+	if err != nil {
+		return "", nil, err
+	}
+	generatedCode, err = g.executeTemplate("structs", structs)
+	if err != nil {
+		return "", nil, err
+	}
+	return generatedCode, importPackages, nil
+}
+
+// readTestRows reads the rows of tabledata.list rowsJSON with the table schema of tables.get tableJSON from a fake BigQuery API.
+func readTestRows(t *testing.T, tableJSON, rowsJSON string) *bigquery.RowIterator {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/data") {
+			_, _ = w.Write([]byte(rowsJSON))
+			return
+		}
+		_, _ = w.Write([]byte(tableJSON))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := bigquery.NewClient(context.Background(), "project", option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client.Dataset("dataset").Table("table").Read(context.Background())
+}
+
+// newTestGenerator returns the Generator of opts, failing t if opts is invalid.
+func newTestGenerator(t *testing.T, opts Options) *Generator {
+	t.Helper()
+	g, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
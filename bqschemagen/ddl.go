@@ -0,0 +1,1067 @@
+package bqschemagen
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// ddlTable is a table defined by a CREATE TABLE statement.
+type ddlTable struct {
+	tableID string
+	md      *bigquery.TableMetadata
+}
+
+type ddlTokenKind int
+
+const (
+	ddlTokenEOF ddlTokenKind = iota
+	ddlTokenIdent
+	ddlTokenQuotedIdent
+	ddlTokenString
+	ddlTokenNumber
+	ddlTokenSymbol
+)
+
+type ddlToken struct {
+	kind ddlTokenKind
+	// text is the identifier, the unquoted string literal, the number or the symbol.
+	text string
+	pos  int
+}
+
+// NOTE(ginokent): ref. https://cloud.google.com/bigquery/docs/reference/standard-sql/data-types
+var ddlFieldTypes = map[string]bigquery.FieldType{
+	"INT64":      bigquery.IntegerFieldType,
+	"INT":        bigquery.IntegerFieldType,
+	"SMALLINT":   bigquery.IntegerFieldType,
+	"INTEGER":    bigquery.IntegerFieldType,
+	"BIGINT":     bigquery.IntegerFieldType,
+	"TINYINT":    bigquery.IntegerFieldType,
+	"BYTEINT":    bigquery.IntegerFieldType,
+	"FLOAT64":    bigquery.FloatFieldType,
+	"FLOAT":      bigquery.FloatFieldType,
+	"BOOL":       bigquery.BooleanFieldType,
+	"BOOLEAN":    bigquery.BooleanFieldType,
+	"STRING":     bigquery.StringFieldType,
+	"BYTES":      bigquery.BytesFieldType,
+	"NUMERIC":    bigquery.NumericFieldType,
+	"DECIMAL":    bigquery.NumericFieldType,
+	"BIGNUMERIC": bigquery.BigNumericFieldType,
+	"BIGDECIMAL": bigquery.BigNumericFieldType,
+	"DATE":       bigquery.DateFieldType,
+	"TIME":       bigquery.TimeFieldType,
+	"DATETIME":   bigquery.DateTimeFieldType,
+	"TIMESTAMP":  bigquery.TimestampFieldType,
+	"GEOGRAPHY":  bigquery.GeographyFieldType,
+	"JSON":       bigquery.JSONFieldType,
+	"INTERVAL":   bigquery.IntervalFieldType,
+}
+
+// NOTE(ginokent): ref. https://cloud.google.com/bigquery/docs/reference/standard-sql/data-definition-language#partition_expression
+var ddlTimePartitioningTypes = map[string]bigquery.TimePartitioningType{
+	"HOUR":  bigquery.HourPartitioningType,
+	"DAY":   bigquery.DayPartitioningType,
+	"MONTH": bigquery.MonthPartitioningType,
+	"YEAR":  bigquery.YearPartitioningType,
+}
+
+// readDDLFiles applies the CREATE TABLE, ALTER TABLE and DROP TABLE statements in the files that match patterns, in file name order like migrations.
+// If a table is created more than once, the last statement wins unless it is CREATE TABLE IF NOT EXISTS.
+func readDDLFiles(patterns []string, logger *log.Logger) (tables []ddlTable, err error) {
+	var paths []string
+	for _, pattern := range patterns {
+		var matches []string
+		matches, err = filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("filepath.Glob: %w", err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no DDL file matches: %s", pattern)
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	tablesByID := make(map[string]*bigquery.TableMetadata)
+	for _, path := range paths {
+		var content []byte
+		content, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("os.ReadFile: %w", err)
+		}
+
+		if err = applyDDL(string(content), tablesByID, logger); err != nil {
+			return nil, fmt.Errorf("applyDDL: path=%s, %w", path, err)
+		}
+	}
+
+	return sortedDDLTables(tablesByID), nil
+}
+
+// parseDDL parses the statements in src like readDDLFiles, and returns the tables.
+func parseDDL(src string) (tables []ddlTable, err error) {
+	tablesByID := make(map[string]*bigquery.TableMetadata)
+	if err = applyDDL(src, tablesByID, log.Default()); err != nil {
+		return nil, err
+	}
+	return sortedDDLTables(tablesByID), nil
+}
+
+// applyDDL applies the CREATE TABLE, ALTER TABLE and DROP TABLE statements in src to tablesByID. The other statements are skipped with a warning.
+func applyDDL(src string, tablesByID map[string]*bigquery.TableMetadata, logger *log.Logger) (err error) {
+	var tokens []ddlToken
+	tokens, err = tokenizeDDL(src)
+	if err != nil {
+		return fmt.Errorf("tokenizeDDL: %w", err)
+	}
+
+	p := &ddlParser{src: src, tokens: tokens, tables: tablesByID, logger: logger}
+	for {
+		for p.acceptSymbol(";") {
+		}
+		if p.peek().kind == ddlTokenEOF {
+			return nil
+		}
+
+		if err = p.parseStatement(); err != nil {
+			return err
+		}
+	}
+}
+
+// sortedDDLTables returns the tables of tablesByID sorted by the table ID.
+func sortedDDLTables(tablesByID map[string]*bigquery.TableMetadata) (tables []ddlTable) {
+	for tableID, md := range tablesByID {
+		tables = append(tables, ddlTable{tableID: tableID, md: md})
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].tableID < tables[j].tableID })
+	return tables
+}
+
+// tokenizeDDL splits src into tokens, skipping whitespaces and comments.
+// NOTE(ginokent): ref. https://cloud.google.com/bigquery/docs/reference/standard-sql/lexical
+func tokenizeDDL(src string) (tokens []ddlToken, err error) {
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '#' || strings.HasPrefix(src[i:], "--"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("%s: unterminated comment", ddlPosition(src, i))
+			}
+			i += 2 + end + 2
+		case c == '`':
+			var text string
+			var n int
+			text, n, err = readDDLQuoted(src, i, "`", false)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, ddlToken{kind: ddlTokenQuotedIdent, text: text, pos: i})
+			i += n
+		case c == '\'' || c == '"' || (strings.ContainsRune("rRbB", rune(c)) && ddlStringPrefixLen(src[i:]) > 0):
+			prefixLen := ddlStringPrefixLen(src[i:])
+			raw := strings.ContainsAny(src[i:i+prefixLen], "rR")
+			quote := src[i+prefixLen : i+prefixLen+1]
+			if strings.HasPrefix(src[i+prefixLen:], quote+quote+quote) {
+				quote = quote + quote + quote
+			}
+			var text string
+			var n int
+			text, n, err = readDDLQuoted(src, i+prefixLen, quote, raw)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, ddlToken{kind: ddlTokenString, text: text, pos: i})
+			i += prefixLen + n
+		case c == '_' || isASCIILetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || isASCIILetter(src[i]) || isASCIIDigit(src[i])) {
+				i++
+			}
+			tokens = append(tokens, ddlToken{kind: ddlTokenIdent, text: src[start:i], pos: start})
+		case isASCIIDigit(c):
+			start := i
+			for i < len(src) && (src[i] == '.' || isASCIILetter(src[i]) || isASCIIDigit(src[i])) {
+				i++
+			}
+			tokens = append(tokens, ddlToken{kind: ddlTokenNumber, text: src[start:i], pos: start})
+		default:
+			tokens = append(tokens, ddlToken{kind: ddlTokenSymbol, text: string(c), pos: i})
+			i++
+		}
+	}
+
+	return append(tokens, ddlToken{kind: ddlTokenEOF, pos: len(src)}), nil
+}
+
+// NOTE(ginokent): unquoted identifiers are ASCII only. ref. https://cloud.google.com/bigquery/docs/reference/standard-sql/lexical#identifiers
+func isASCIILetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// ddlStringPrefixLen returns the length of the string literal prefix (e.g. `r`, `b`, `rb`) at the head of s, or 0 if s does not start a prefixed string literal.
+func ddlStringPrefixLen(s string) int {
+	for n := 1; n <= 2 && n < len(s); n++ {
+		if !strings.ContainsRune("rRbB", rune(s[n-1])) {
+			return 0
+		}
+		if s[n] == '\'' || s[n] == '"' {
+			return n
+		}
+	}
+	return 0
+}
+
+// readDDLQuoted reads the literal quoted by quote at src[start:], and returns the unescaped text and the length of the literal.
+func readDDLQuoted(src string, start int, quote string, raw bool) (text string, n int, err error) {
+	var b strings.Builder
+	i := start + len(quote)
+	for {
+		if i >= len(src) {
+			return "", 0, fmt.Errorf("%s: unterminated literal", ddlPosition(src, start))
+		}
+		if strings.HasPrefix(src[i:], quote) {
+			return b.String(), i + len(quote) - start, nil
+		}
+		if len(quote) == 1 && src[i] == '\n' {
+			return "", 0, fmt.Errorf("%s: unterminated literal", ddlPosition(src, start))
+		}
+		if src[i] == '\\' && i+1 < len(src) {
+			if raw {
+				b.WriteString(src[i : i+2])
+			} else {
+				b.WriteString(unescapeDDL(src[i+1]))
+			}
+			i += 2
+			continue
+		}
+		b.WriteByte(src[i])
+		i++
+	}
+}
+
+// NOTE(ginokent): ref. https://cloud.google.com/bigquery/docs/reference/standard-sql/lexical#escape_sequences
+func unescapeDDL(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case 'a':
+		return "\a"
+	case 'b':
+		return "\b"
+	case 'f':
+		return "\f"
+	case 'v':
+		return "\v"
+	default:
+		return string(c)
+	}
+}
+
+// ddlPosition returns `line:column` of pos in src.
+func ddlPosition(src string, pos int) string {
+	line := strings.Count(src[:pos], "\n") + 1
+	column := pos - strings.LastIndex(src[:pos], "\n")
+	return strconv.Itoa(line) + ":" + strconv.Itoa(column)
+}
+
+type ddlParser struct {
+	src    string
+	tokens []ddlToken
+	i      int
+	// tables are the tables that the statements are applied to, keyed by the table ID.
+	tables map[string]*bigquery.TableMetadata
+	// logger receives the warnings of the skipped statements.
+	logger *log.Logger
+}
+
+func (p *ddlParser) peek() ddlToken {
+	return p.tokens[p.i]
+}
+
+func (p *ddlParser) next() ddlToken {
+	token := p.tokens[p.i]
+	if token.kind != ddlTokenEOF {
+		p.i++
+	}
+	return token
+}
+
+func (p *ddlParser) isKeyword(keyword string) bool {
+	token := p.peek()
+	return token.kind == ddlTokenIdent && strings.EqualFold(token.text, keyword)
+}
+
+func (p *ddlParser) acceptKeyword(keywords ...string) bool {
+	start := p.i
+	for _, keyword := range keywords {
+		if !p.isKeyword(keyword) {
+			p.i = start
+			return false
+		}
+		p.next()
+	}
+	return true
+}
+
+func (p *ddlParser) expectKeyword(keywords ...string) error {
+	if !p.acceptKeyword(keywords...) {
+		return p.errorf("expected %s", strings.Join(keywords, " "))
+	}
+	return nil
+}
+
+func (p *ddlParser) isSymbol(symbol string) bool {
+	return p.isSymbolAt(0, symbol)
+}
+
+// isSymbolAt reports whether the token at offset from the current one is symbol.
+func (p *ddlParser) isSymbolAt(offset int, symbol string) bool {
+	if p.i+offset >= len(p.tokens) {
+		return false
+	}
+	token := p.tokens[p.i+offset]
+	return token.kind == ddlTokenSymbol && token.text == symbol
+}
+
+func (p *ddlParser) acceptSymbol(symbol string) bool {
+	if !p.isSymbol(symbol) {
+		return false
+	}
+	p.next()
+	return true
+}
+
+func (p *ddlParser) expectSymbol(symbol string) error {
+	if !p.acceptSymbol(symbol) {
+		return p.errorf("expected `%s`", symbol)
+	}
+	return nil
+}
+
+func (p *ddlParser) errorf(format string, a ...interface{}) error {
+	token := p.peek()
+	found := "EOF"
+	if token.kind != ddlTokenEOF {
+		found = "`" + strings.TrimSpace(p.src[token.pos:p.tokens[p.i+1].pos]) + "`"
+	}
+	return fmt.Errorf("%s: %s, found %s", ddlPosition(p.src, token.pos), fmt.Sprintf(format, a...), found)
+}
+
+// skipUntil skips tokens until `;`, EOF or one of the symbols or keywords of stops at the depth 0 of parentheses and brackets.
+func (p *ddlParser) skipUntil(stops ...string) {
+	depth := 0
+	for {
+		token := p.peek()
+		if token.kind == ddlTokenEOF || (depth == 0 && p.isSymbol(";")) {
+			return
+		}
+		if depth == 0 {
+			for _, stop := range stops {
+				if p.isSymbol(stop) || p.isKeyword(stop) {
+					return
+				}
+			}
+		}
+		if token.kind == ddlTokenSymbol {
+			switch token.text {
+			case "(", "[":
+				depth++
+			case ")", "]":
+				depth--
+			}
+		}
+		p.next()
+	}
+}
+
+// parseIdent parses an identifier or a quoted identifier.
+func (p *ddlParser) parseIdent() (ident string, err error) {
+	token := p.peek()
+	if token.kind != ddlTokenIdent && token.kind != ddlTokenQuotedIdent {
+		return "", p.errorf("expected identifier")
+	}
+	p.next()
+	return token.text, nil
+}
+
+// parseTableName parses `[[project.]dataset.]table`, where each part or the whole name may be quoted.
+// The unquoted project may contain dashes (e.g. `my-project.dataset.table`).
+func (p *ddlParser) parseTableName() (names []string, err error) {
+	for {
+		token := p.peek()
+		var ident string
+		ident, err = p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		if token.kind == ddlTokenIdent {
+			ident = p.parseDashedIdent(ident, token.pos+len(token.text))
+		}
+		names = append(names, strings.Split(ident, ".")...)
+		if !p.acceptSymbol(".") {
+			return names, nil
+		}
+	}
+}
+
+// parseDashedIdent appends the `-` separated parts that follow the unquoted identifier ident ending at end without spaces (e.g. `my-project-1`).
+// NOTE(ginokent): ref. https://cloud.google.com/bigquery/docs/reference/standard-sql/lexical#path_expressions
+func (p *ddlParser) parseDashedIdent(ident string, end int) string {
+	for p.isSymbol("-") && p.peek().pos == end && p.i+1 < len(p.tokens) {
+		part := p.tokens[p.i+1]
+		if (part.kind != ddlTokenIdent && part.kind != ddlTokenNumber) || part.pos != end+1 {
+			break
+		}
+		p.next()
+		p.next()
+		// NOTE(ginokent): a number token may contain the following `.dataset.table`, which parseTableName splits.
+		ident += "-" + part.text
+		end = part.pos + len(part.text)
+	}
+	return ident
+}
+
+// parseTableNameID parses the table name, and returns the table ID and the full ID for the comments.
+func (p *ddlParser) parseTableNameID() (tableID, fullID string, err error) {
+	var names []string
+	names, err = p.parseTableName()
+	if err != nil {
+		return "", "", err
+	}
+
+	tableID = names[len(names)-1]
+	switch len(names) {
+	case 2:
+		fullID = tableFullID("", names[0], tableID)
+	case 3:
+		fullID = tableFullID(names[0], names[1], tableID)
+	}
+	return tableID, fullID, nil
+}
+
+// parseStatement parses a statement and applies it to p.tables.
+// The statements other than CREATE TABLE with a column list, ALTER TABLE and DROP TABLE are skipped with a warning.
+func (p *ddlParser) parseStatement() (err error) {
+	start := p.peek()
+	switch {
+	case p.acceptKeyword("CREATE"):
+		return p.parseCreateTable(start)
+	case p.acceptKeyword("ALTER", "TABLE"):
+		return p.parseAlterTable(start)
+	case p.acceptKeyword("DROP", "TABLE"):
+		return p.parseDropTable(start)
+	default:
+		p.skipStatement(start, "not a CREATE, ALTER or DROP TABLE statement")
+		return nil
+	}
+}
+
+// parseCreateTable parses the statement after CREATE.
+func (p *ddlParser) parseCreateTable(start ddlToken) (err error) {
+	replace := p.acceptKeyword("OR", "REPLACE")
+	if !p.acceptKeyword("TEMP") {
+		p.acceptKeyword("TEMPORARY")
+	}
+	if !p.acceptKeyword("TABLE") {
+		p.skipStatement(start, "not a CREATE TABLE statement")
+		return nil
+	}
+	ifNotExists := p.acceptKeyword("IF", "NOT", "EXISTS")
+
+	tableID, fullID, err := p.parseTableNameID()
+	if err != nil {
+		return err
+	}
+	md := &bigquery.TableMetadata{FullID: fullID}
+
+	if !p.acceptSymbol("(") {
+		p.skipStatement(start, "CREATE TABLE `"+tableID+"` has no column list")
+		return nil
+	}
+	md.Schema, err = p.parseColumns()
+	if err != nil {
+		return fmt.Errorf("tableID=%s, %w", tableID, err)
+	}
+
+	for !p.isSymbol(";") && p.peek().kind != ddlTokenEOF {
+		switch {
+		case p.acceptKeyword("DEFAULT", "COLLATE"):
+			p.next()
+		case p.acceptKeyword("PARTITION", "BY"):
+			if err = p.parsePartitioning(md); err != nil {
+				return fmt.Errorf("tableID=%s, %w", tableID, err)
+			}
+		case p.acceptKeyword("CLUSTER", "BY"):
+			if md.Clustering, err = p.parseClustering(); err != nil {
+				return fmt.Errorf("tableID=%s, %w", tableID, err)
+			}
+		case p.acceptKeyword("OPTIONS"):
+			var options map[string]string
+			if options, err = p.parseOptions(); err != nil {
+				return fmt.Errorf("tableID=%s, %w", tableID, err)
+			}
+			md.Description = options["description"]
+		case p.acceptKeyword("AS"):
+			p.skipUntil()
+		default:
+			return fmt.Errorf("tableID=%s, %w", tableID, p.errorf("unexpected clause"))
+		}
+	}
+
+	if _, ok := p.tables[tableID]; ok {
+		if ifNotExists {
+			infoln(p.logger, fmt.Sprintf("%s: table `%s` already exists. skipping CREATE TABLE IF NOT EXISTS", ddlPosition(p.src, start.pos), tableID))
+			return nil
+		}
+		if !replace {
+			infoln(p.logger, fmt.Sprintf("%s: table `%s` is created again. using the last definition", ddlPosition(p.src, start.pos), tableID))
+		}
+	}
+	p.tables[tableID] = md
+	return nil
+}
+
+// parseAlterTable parses the statement after ALTER TABLE, and applies the actions to the table.
+// NOTE(ginokent): ref. https://cloud.google.com/bigquery/docs/reference/standard-sql/data-definition-language#alter_table_set_options_statement
+func (p *ddlParser) parseAlterTable(start ddlToken) (err error) {
+	ifExists := p.acceptKeyword("IF", "EXISTS")
+	tableID, _, err := p.parseTableNameID()
+	if err != nil {
+		return err
+	}
+
+	md, ok := p.tables[tableID]
+	if !ok {
+		if ifExists {
+			p.skipUntil()
+			return nil
+		}
+		p.skipStatement(start, "table `"+tableID+"` is not created by the preceding statements")
+		return nil
+	}
+
+	for {
+		if err = p.parseAlterTableAction(tableID, md); err != nil {
+			return fmt.Errorf("tableID=%s, %w", tableID, err)
+		}
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	if !p.isSymbol(";") && p.peek().kind != ddlTokenEOF {
+		return fmt.Errorf("tableID=%s, %w", tableID, p.errorf("expected `,` or `;`"))
+	}
+	return nil
+}
+
+// parseAlterTableAction parses an action of ALTER TABLE, and applies it to md.
+// The actions that do not change the schema, such as ADD PRIMARY KEY, are skipped with a warning.
+func (p *ddlParser) parseAlterTableAction(tableID string, md *bigquery.TableMetadata) (err error) {
+	start := p.peek()
+	switch {
+	case p.acceptKeyword("ADD", "COLUMN"):
+		ifNotExists := p.acceptKeyword("IF", "NOT", "EXISTS")
+		var fieldSchema *bigquery.FieldSchema
+		fieldSchema, err = p.parseField(";")
+		if err != nil {
+			return err
+		}
+		if ddlFieldIndex(md.Schema, fieldSchema.Name) >= 0 {
+			if ifNotExists {
+				return nil
+			}
+			return fmt.Errorf("%s: column `%s` already exists", ddlPosition(p.src, start.pos), fieldSchema.Name)
+		}
+		md.Schema = append(md.Schema, fieldSchema)
+
+	case p.acceptKeyword("DROP", "COLUMN"):
+		ifExists := p.acceptKeyword("IF", "EXISTS")
+		var i int
+		if i, err = p.parseColumnIndex(md, ifExists); err != nil || i < 0 {
+			return err
+		}
+		md.Schema = append(md.Schema[:i:i], md.Schema[i+1:]...)
+
+	case p.acceptKeyword("RENAME", "COLUMN"):
+		ifExists := p.acceptKeyword("IF", "EXISTS")
+		var i int
+		i, err = p.parseColumnIndex(md, ifExists)
+		if err != nil {
+			return err
+		}
+		if err = p.expectKeyword("TO"); err != nil {
+			return err
+		}
+		var name string
+		if name, err = p.parseIdent(); err != nil {
+			return err
+		}
+		if i >= 0 {
+			md.Schema[i].Name = name
+		}
+
+	case p.acceptKeyword("RENAME", "TO"):
+		var newTableID, fullID string
+		newTableID, fullID, err = p.parseTableNameID()
+		if err != nil {
+			return err
+		}
+		if fullID == "" && md.FullID != "" {
+			fullID = md.FullID[:len(md.FullID)-len(tableID)] + newTableID
+		}
+		md.FullID = fullID
+		delete(p.tables, tableID)
+		p.tables[newTableID] = md
+
+	case p.acceptKeyword("SET", "OPTIONS"):
+		var options map[string]string
+		if options, err = p.parseOptions(); err != nil {
+			return err
+		}
+		if description, ok := options["description"]; ok {
+			md.Description = description
+		}
+
+	case p.acceptKeyword("ALTER", "COLUMN"):
+		ifExists := p.acceptKeyword("IF", "EXISTS")
+		var i int
+		i, err = p.parseColumnIndex(md, ifExists)
+		if err != nil {
+			return err
+		}
+		// NOTE(ginokent): the action is parsed for a missing column with IF EXISTS as well, and applied to a dummy.
+		fieldSchema := &bigquery.FieldSchema{}
+		if i >= 0 {
+			fieldSchema = md.Schema[i]
+		}
+		if err = p.parseAlterColumnAction(fieldSchema); err != nil {
+			return err
+		}
+
+	default:
+		p.skipUntil(",")
+		warnln(p.logger, fmt.Sprintf("%s: ALTER TABLE action that does not change the schema. skipping `%s`", ddlPosition(p.src, start.pos), p.statementText(start)))
+	}
+	return nil
+}
+
+// parseAlterColumnAction parses the action after ALTER COLUMN, and applies it to fieldSchema.
+func (p *ddlParser) parseAlterColumnAction(fieldSchema *bigquery.FieldSchema) (err error) {
+	switch {
+	case p.acceptKeyword("SET", "OPTIONS"):
+		var options map[string]string
+		if options, err = p.parseOptions(); err != nil {
+			return err
+		}
+		if description, ok := options["description"]; ok {
+			fieldSchema.Description = description
+		}
+	case p.acceptKeyword("DROP", "NOT", "NULL"):
+		fieldSchema.Required = false
+	case p.acceptKeyword("SET", "DATA", "TYPE"):
+		var typed *bigquery.FieldSchema
+		if typed, err = p.parseType(); err != nil {
+			return err
+		}
+		fieldSchema.Type, fieldSchema.Schema, fieldSchema.RangeElementType = typed.Type, typed.Schema, typed.RangeElementType
+		fieldSchema.MaxLength, fieldSchema.Precision, fieldSchema.Scale = typed.MaxLength, typed.Precision, typed.Scale
+	case p.acceptKeyword("SET", "DEFAULT"):
+		start := p.peek().pos
+		p.skipUntil(",")
+		fieldSchema.DefaultValueExpression = strings.TrimSpace(p.src[start:p.peek().pos])
+	case p.acceptKeyword("DROP", "DEFAULT"):
+		fieldSchema.DefaultValueExpression = ""
+	default:
+		return p.errorf("unexpected ALTER COLUMN action")
+	}
+	return nil
+}
+
+// parseColumnIndex parses a column name, and returns the index of the column in md.Schema.
+// If the column does not exist, it returns -1 with ifExists, or an error without it.
+func (p *ddlParser) parseColumnIndex(md *bigquery.TableMetadata, ifExists bool) (i int, err error) {
+	token := p.peek()
+	var name string
+	if name, err = p.parseIdent(); err != nil {
+		return -1, err
+	}
+	if i = ddlFieldIndex(md.Schema, name); i < 0 && !ifExists {
+		return -1, fmt.Errorf("%s: column `%s` not found", ddlPosition(p.src, token.pos), name)
+	}
+	return i, nil
+}
+
+// ddlFieldIndex returns the index of the column name in schema, or -1. The column names are case-insensitive.
+func ddlFieldIndex(schema bigquery.Schema, name string) int {
+	for i, fieldSchema := range schema {
+		if strings.EqualFold(fieldSchema.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseDropTable parses the statement after DROP TABLE, and removes the table.
+func (p *ddlParser) parseDropTable(start ddlToken) (err error) {
+	ifExists := p.acceptKeyword("IF", "EXISTS")
+	tableID, _, err := p.parseTableNameID()
+	if err != nil {
+		return err
+	}
+	if _, ok := p.tables[tableID]; !ok && !ifExists {
+		p.skipStatement(start, "table `"+tableID+"` is not created by the preceding statements")
+		return nil
+	}
+	delete(p.tables, tableID)
+	p.skipUntil()
+	return nil
+}
+
+// skipStatement skips the statement that starts at start with a warning of reason.
+func (p *ddlParser) skipStatement(start ddlToken, reason string) {
+	p.skipUntil()
+	warnln(p.logger, fmt.Sprintf("%s: %s. skipping `%s`", ddlPosition(p.src, start.pos), reason, p.statementText(start)))
+}
+
+// statementText returns the text from start to the current token for the messages, which is shortened to 40 bytes.
+func (p *ddlParser) statementText(start ddlToken) string {
+	statement := strings.Join(strings.Fields(p.src[start.pos:p.peek().pos]), " ")
+	if len(statement) > 40 {
+		statement = statement[:40] + "..."
+	}
+	return statement
+}
+
+// parseColumns parses the column list after `(` until `)`. The table constraints are skipped.
+func (p *ddlParser) parseColumns() (schema bigquery.Schema, err error) {
+	for {
+		if p.isKeyword("PRIMARY") || p.isKeyword("FOREIGN") || p.isKeyword("CONSTRAINT") {
+			p.skipUntil(",", ")")
+		} else {
+			var fieldSchema *bigquery.FieldSchema
+			fieldSchema, err = p.parseField(")")
+			if err != nil {
+				return nil, err
+			}
+			schema = append(schema, fieldSchema)
+		}
+
+		if p.acceptSymbol(")") {
+			return schema, nil
+		}
+		if err = p.expectSymbol(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseField parses `name type [NOT NULL] [DEFAULT expr] [OPTIONS(...)] ...` followed by `,` or end.
+func (p *ddlParser) parseField(end string) (fieldSchema *bigquery.FieldSchema, err error) {
+	var name string
+	name, err = p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	fieldSchema, err = p.parseType()
+	if err != nil {
+		return nil, fmt.Errorf("column=%s, %w", name, err)
+	}
+	fieldSchema.Name = name
+
+	for !p.isSymbol(",") && !p.isSymbol(end) && p.peek().kind != ddlTokenEOF {
+		switch {
+		case p.acceptKeyword("NOT", "NULL"):
+			// NOTE(ginokent): ARRAY can not be NOT NULL, and REPEATED fields are never NULL anyway.
+			fieldSchema.Required = !fieldSchema.Repeated
+		case p.acceptKeyword("OPTIONS"):
+			var options map[string]string
+			if options, err = p.parseOptions(); err != nil {
+				return nil, fmt.Errorf("column=%s, %w", name, err)
+			}
+			fieldSchema.Description = options["description"]
+		case p.acceptKeyword("DEFAULT"):
+			start := p.peek().pos
+			p.skipUntil(",", end, "OPTIONS", "NOT")
+			fieldSchema.DefaultValueExpression = strings.TrimSpace(p.src[start:p.peek().pos])
+		case p.acceptKeyword("COLLATE"):
+			p.next()
+		case p.acceptKeyword("PRIMARY", "KEY", "NOT", "ENFORCED"):
+		case p.acceptKeyword("REFERENCES"):
+			p.skipUntil(",", end, "OPTIONS", "NOT")
+			p.acceptKeyword("NOT", "ENFORCED")
+		default:
+			return nil, fmt.Errorf("column=%s, %w", name, p.errorf("unexpected column attribute"))
+		}
+	}
+
+	return fieldSchema, nil
+}
+
+// parseType parses a data type such as `INT64`, `STRING(10)`, `NUMERIC(10, 2)`, `ARRAY<T>`, `STRUCT<a T, ...>` or `RANGE<DATE>`.
+// NOTE(ginokent): ref. https://cloud.google.com/bigquery/docs/reference/standard-sql/data-definition-language#column_name_and_column_schema
+func (p *ddlParser) parseType() (fieldSchema *bigquery.FieldSchema, err error) {
+	token := p.peek()
+	if token.kind != ddlTokenIdent {
+		return nil, p.errorf("expected data type")
+	}
+	p.next()
+
+	switch typeName := strings.ToUpper(token.text); typeName {
+	case "ARRAY":
+		if err = p.expectSymbol("<"); err != nil {
+			return nil, err
+		}
+		fieldSchema, err = p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if fieldSchema.Repeated {
+			return nil, p.errorf("ARRAY of ARRAY is not supported")
+		}
+		fieldSchema.Repeated = true
+		if err = p.expectSymbol(">"); err != nil {
+			return nil, err
+		}
+		return fieldSchema, nil
+
+	case "STRUCT", "RECORD":
+		fieldSchema = &bigquery.FieldSchema{Type: bigquery.RecordFieldType}
+		if err = p.expectSymbol("<"); err != nil {
+			return nil, err
+		}
+		for {
+			var nested *bigquery.FieldSchema
+			nested, err = p.parseField(">")
+			if err != nil {
+				return nil, err
+			}
+			fieldSchema.Schema = append(fieldSchema.Schema, nested)
+			if p.acceptSymbol(">") {
+				return fieldSchema, nil
+			}
+			if err = p.expectSymbol(","); err != nil {
+				return nil, err
+			}
+		}
+
+	case "RANGE":
+		if err = p.expectSymbol("<"); err != nil {
+			return nil, err
+		}
+		var element *bigquery.FieldSchema
+		element, err = p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expectSymbol(">"); err != nil {
+			return nil, err
+		}
+		return &bigquery.FieldSchema{Type: bigquery.RangeFieldType, RangeElementType: &bigquery.RangeElementType{Type: element.Type}}, nil
+
+	default:
+		fieldType, ok := ddlFieldTypes[typeName]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown data type `%s`", ddlPosition(p.src, token.pos), token.text)
+		}
+		fieldSchema = &bigquery.FieldSchema{Type: fieldType}
+		if !p.acceptSymbol("(") {
+			return fieldSchema, nil
+		}
+
+		var params []int64
+		params, err = p.parseTypeParameters()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case (fieldType == bigquery.StringFieldType || fieldType == bigquery.BytesFieldType) && len(params) == 1:
+			fieldSchema.MaxLength = params[0]
+		case (fieldType == bigquery.NumericFieldType || fieldType == bigquery.BigNumericFieldType) && len(params) == 1:
+			fieldSchema.Precision = params[0]
+		case (fieldType == bigquery.NumericFieldType || fieldType == bigquery.BigNumericFieldType) && len(params) == 2:
+			fieldSchema.Precision, fieldSchema.Scale = params[0], params[1]
+		default:
+			return nil, fmt.Errorf("%s: invalid parameters for `%s`", ddlPosition(p.src, token.pos), token.text)
+		}
+		return fieldSchema, nil
+	}
+}
+
+// parseTypeParameters parses the integers after `(` until `)`.
+func (p *ddlParser) parseTypeParameters() (params []int64, err error) {
+	for {
+		token := p.peek()
+		if token.kind != ddlTokenNumber {
+			return nil, p.errorf("expected number")
+		}
+		p.next()
+
+		var param int64
+		param, err = strconv.ParseInt(token.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: strconv.ParseInt: %w", ddlPosition(p.src, token.pos), err)
+		}
+		params = append(params, param)
+
+		if p.acceptSymbol(")") {
+			return params, nil
+		}
+		if err = p.expectSymbol(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseOptions parses `(name = value, ...)` after OPTIONS, and returns the options whose values are string literals.
+func (p *ddlParser) parseOptions() (options map[string]string, err error) {
+	options = make(map[string]string)
+	if err = p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	if p.acceptSymbol(")") {
+		return options, nil
+	}
+
+	for {
+		var name string
+		name, err = p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expectSymbol("="); err != nil {
+			return nil, err
+		}
+
+		if token := p.peek(); token.kind == ddlTokenString && (p.isSymbolAt(1, ",") || p.isSymbolAt(1, ")")) {
+			options[strings.ToLower(name)] = token.text
+			p.next()
+		} else {
+			p.skipUntil(",", ")")
+		}
+
+		if p.acceptSymbol(")") {
+			return options, nil
+		}
+		if err = p.expectSymbol(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parsePartitioning parses the partition expression after PARTITION BY.
+func (p *ddlParser) parsePartitioning(md *bigquery.TableMetadata) (err error) {
+	var function string
+	if token := p.peek(); token.kind == ddlTokenIdent && p.isSymbolAt(1, "(") {
+		function = strings.ToUpper(token.text)
+		p.next()
+		p.next()
+	}
+
+	var column string
+	column, err = p.parseIdent()
+	if err != nil {
+		return err
+	}
+	// NOTE(ginokent): ingestion-time partitioning has no partitioning column.
+	if strings.EqualFold(column, "_PARTITIONDATE") || strings.EqualFold(column, "_PARTITIONTIME") {
+		column = ""
+	}
+
+	switch function {
+	case "":
+		md.TimePartitioning = &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: column}
+		return nil
+	case "DATE":
+		md.TimePartitioning = &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: column}
+	case "DATE_TRUNC", "DATETIME_TRUNC", "TIMESTAMP_TRUNC":
+		if err = p.expectSymbol(","); err != nil {
+			return err
+		}
+		granularity := p.next()
+		partitioningType, ok := ddlTimePartitioningTypes[strings.ToUpper(granularity.text)]
+		if !ok {
+			return fmt.Errorf("%s: unknown partitioning granularity `%s`", ddlPosition(p.src, granularity.pos), granularity.text)
+		}
+		md.TimePartitioning = &bigquery.TimePartitioning{Type: partitioningType, Field: column}
+	case "RANGE_BUCKET":
+		if err = p.expectSymbol(","); err != nil {
+			return err
+		}
+		if err = p.expectKeyword("GENERATE_ARRAY"); err != nil {
+			return err
+		}
+		if err = p.expectSymbol("("); err != nil {
+			return err
+		}
+		var bounds []int64
+		for len(bounds) < 3 {
+			if len(bounds) > 0 {
+				if err = p.expectSymbol(","); err != nil {
+					return err
+				}
+			}
+			negative := p.acceptSymbol("-")
+			token := p.next()
+			var bound int64
+			bound, err = strconv.ParseInt(token.text, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s: strconv.ParseInt: %w", ddlPosition(p.src, token.pos), err)
+			}
+			if negative {
+				bound = -bound
+			}
+			bounds = append(bounds, bound)
+		}
+		if err = p.expectSymbol(")"); err != nil {
+			return err
+		}
+		md.RangePartitioning = &bigquery.RangePartitioning{
+			Field: column,
+			Range: &bigquery.RangePartitioningRange{Start: bounds[0], End: bounds[1], Interval: bounds[2]},
+		}
+	default:
+		return fmt.Errorf("%s: unknown partitioning function `%s`", ddlPosition(p.src, p.tokens[p.i-2].pos), function)
+	}
+
+	return p.expectSymbol(")")
+}
+
+// parseClustering parses the column names after CLUSTER BY.
+func (p *ddlParser) parseClustering() (clustering *bigquery.Clustering, err error) {
+	clustering = &bigquery.Clustering{}
+	for {
+		var column string
+		column, err = p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		clustering.Fields = append(clustering.Fields, column)
+		if !p.acceptSymbol(",") {
+			return clustering, nil
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package bqschemagen
+
+import (
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+const (
+	// readDDLFiles
+	testDDLPattern         = "../test/ddl/*.sql"
+	testDDLPatternNotFound = "../test/ddl/*.notfound"
+)
+
+func Test_readDDLFiles(t *testing.T) {
+	t.Run("正常系_testDDLPattern", func(t *testing.T) {
+		tables, err := readDDLFiles([]string{testDDLPattern}, log.Default())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tables) != 2 || tables[0].tableID != "events" || tables[1].tableID != "users" {
+			t.Fatalf("readDDLFiles: tables=%#v", tables)
+		}
+		users := tables[1].md
+		if users.FullID != "testdataset.users" || users.Description != "users table" || len(users.Schema) != 5 {
+			t.Fatalf("readDDLFiles: users=%#v", users)
+		}
+		// NOTE(ginokent): ALTER TABLE ADD COLUMN in 002_alter_users.sql is applied to the table created in 001_create_users.sql.
+		if deletedAt := users.Schema[4]; deletedAt.Name != "deleted_at" || deletedAt.Type != bigquery.TimestampFieldType {
+			t.Errorf("readDDLFiles: deleted_at=%#v", deletedAt)
+		}
+	})
+
+	t.Run("異常系_testDDLPatternNotFound", func(t *testing.T) {
+		if _, err := readDDLFiles([]string{testDDLPatternNotFound}, log.Default()); err == nil {
+			t.Error(err)
+		}
+	})
+}
+
+func Test_parseDDL(t *testing.T) {
+	t.Run("正常系_nested_STRUCT_ARRAY", func(t *testing.T) {
+		tables, err := parseDDL("CREATE TABLE t (col STRUCT<a INT64, b ARRAY<STRING>, c ARRAY<STRUCT<d DATE>>> NOT NULL OPTIONS(description='a\\'s \"col\"'))")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := bigquery.Schema{
+			{
+				Name:        "col",
+				Type:        bigquery.RecordFieldType,
+				Required:    true,
+				Description: `a's "col"`,
+				Schema: bigquery.Schema{
+					{Name: "a", Type: bigquery.IntegerFieldType},
+					{Name: "b", Type: bigquery.StringFieldType, Repeated: true},
+					{Name: "c", Type: bigquery.RecordFieldType, Repeated: true, Schema: bigquery.Schema{
+						{Name: "d", Type: bigquery.DateFieldType},
+					}},
+				},
+			},
+		}
+		if len(tables) != 1 || !reflect.DeepEqual(tables[0].md.Schema, want) {
+			t.Errorf("parseDDL: want=%#v current=%#v", want, tables)
+		}
+	})
+
+	t.Run("正常系_column_options", func(t *testing.T) {
+		tables, err := parseDDL(`
+# hash comment
+CREATE TEMP TABLE ` + "`project-1.dataset.t`" + ` (
+  /* block
+     comment */
+  a STRING(10) DEFAULT 'x' OPTIONS(description="""multi
+line""", rounding_mode='ROUND_HALF_EVEN'),
+  b BIGNUMERIC(40, 10) COLLATE 'und:ci',
+  c RANGE<DATE>,
+  d INT64 PRIMARY KEY NOT ENFORCED,
+  e JSON,
+  PRIMARY KEY (d) NOT ENFORCED
+)`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		md := tables[0].md
+		if md.FullID != "project-1:dataset.t" {
+			t.Errorf("parseDDL: FullID=%s", md.FullID)
+		}
+		if a := md.Schema[0]; a.Type != bigquery.StringFieldType || a.MaxLength != 10 || a.DefaultValueExpression != "'x'" || a.Description != "multi\nline" {
+			t.Errorf("parseDDL: a=%#v", a)
+		}
+		if b := md.Schema[1]; b.Type != bigquery.BigNumericFieldType || b.Precision != 40 || b.Scale != 10 {
+			t.Errorf("parseDDL: b=%#v", b)
+		}
+		if c := md.Schema[2]; c.Type != bigquery.RangeFieldType || c.RangeElementType == nil || c.RangeElementType.Type != bigquery.DateFieldType {
+			t.Errorf("parseDDL: c=%#v", c)
+		}
+		if len(md.Schema) != 5 || md.Schema[3].Type != bigquery.IntegerFieldType || md.Schema[4].Type != bigquery.JSONFieldType {
+			t.Errorf("parseDDL: schema=%#v", md.Schema)
+		}
+	})
+
+	t.Run("正常系_PARTITION_BY_CLUSTER_BY", func(t *testing.T) {
+		tests := []struct {
+			partitionBy string
+			time        *bigquery.TimePartitioning
+			rng         *bigquery.RangePartitioning
+		}{
+			{"ts", &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: "ts"}, nil},
+			{"DATE(ts)", &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: "ts"}, nil},
+			{"TIMESTAMP_TRUNC(ts, HOUR)", &bigquery.TimePartitioning{Type: bigquery.HourPartitioningType, Field: "ts"}, nil},
+			{"DATE_TRUNC(ts, MONTH)", &bigquery.TimePartitioning{Type: bigquery.MonthPartitioningType, Field: "ts"}, nil},
+			{"_PARTITIONDATE", &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType}, nil},
+			{"RANGE_BUCKET(id, GENERATE_ARRAY(0, 100, 10))", nil, &bigquery.RangePartitioning{Field: "id", Range: &bigquery.RangePartitioningRange{Start: 0, End: 100, Interval: 10}}},
+		}
+		for _, tt := range tests {
+			tables, err := parseDDL("CREATE TABLE t (id INT64, ts TIMESTAMP) PARTITION BY " + tt.partitionBy + " CLUSTER BY id, ts OPTIONS(description='t')")
+			if err != nil {
+				t.Errorf("parseDDL: partitionBy=%s, %v", tt.partitionBy, err)
+				continue
+			}
+			md := tables[0].md
+			if !reflect.DeepEqual(md.TimePartitioning, tt.time) || !reflect.DeepEqual(md.RangePartitioning, tt.rng) {
+				t.Errorf("parseDDL: partitionBy=%s time=%#v range=%#v", tt.partitionBy, md.TimePartitioning, md.RangePartitioning)
+			}
+			if md.Clustering == nil || !reflect.DeepEqual(md.Clustering.Fields, []string{"id", "ts"}) || md.Description != "t" {
+				t.Errorf("parseDDL: partitionBy=%s md=%#v", tt.partitionBy, md)
+			}
+		}
+	})
+
+	t.Run("正常系_skip_other_statements", func(t *testing.T) {
+		tables, err := parseDDL(`
+CREATE VIEW v AS SELECT 1;
+INSERT INTO t (a) VALUES ('(;)');
+CREATE TABLE copied AS SELECT * FROM t;
+CREATE TABLE t (a INT64) AS SELECT 1 AS a;
+DROP TABLE old;`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tables) != 1 || tables[0].tableID != "t" {
+			t.Errorf("parseDDL: tables=%#v", tables)
+		}
+	})
+
+	t.Run("正常系_ALTER_TABLE", func(t *testing.T) {
+		tables, err := parseDDL(`
+CREATE TABLE ds.users (id INT64 NOT NULL, name STRING, legacy STRING, age INT64 NOT NULL);
+ALTER TABLE ds.users
+  ADD COLUMN email STRING NOT NULL OPTIONS(description='e-mail'),
+  ADD COLUMN IF NOT EXISTS NAME STRING,
+  DROP COLUMN legacy,
+  DROP COLUMN IF EXISTS notfound,
+  RENAME COLUMN name TO display_name,
+  ALTER COLUMN age DROP NOT NULL,
+  ALTER COLUMN age SET DATA TYPE NUMERIC(10, 2),
+  ALTER COLUMN id SET OPTIONS(description='user id'),
+  ALTER COLUMN IF EXISTS notfound SET DEFAULT 'x',
+  SET OPTIONS(description='users table'),
+  ADD PRIMARY KEY (id) NOT ENFORCED;
+ALTER TABLE IF EXISTS ds.notfound ADD COLUMN a STRING;
+CREATE TABLE IF NOT EXISTS ds.users (id INT64);
+CREATE TABLE ds.old (id INT64);
+ALTER TABLE ds.old RENAME TO renamed;
+CREATE TABLE tmp (id INT64);
+DROP TABLE IF EXISTS tmp;
+DROP TABLE IF EXISTS notfound`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tables) != 2 || tables[0].tableID != "renamed" || tables[0].md.FullID != "ds.renamed" || tables[1].tableID != "users" {
+			t.Fatalf("parseDDL: tables=%#v", tables)
+		}
+		want := bigquery.Schema{
+			{Name: "id", Type: bigquery.IntegerFieldType, Required: true, Description: "user id"},
+			{Name: "display_name", Type: bigquery.StringFieldType},
+			{Name: "age", Type: bigquery.NumericFieldType, Precision: 10, Scale: 2},
+			{Name: "email", Type: bigquery.StringFieldType, Required: true, Description: "e-mail"},
+		}
+		if md := tables[1].md; !reflect.DeepEqual(md.Schema, want) || md.Description != "users table" {
+			t.Errorf("parseDDL: want=%#v current=%#v", want, md)
+		}
+	})
+
+	t.Run("正常系_unquoted_project_with_dashes", func(t *testing.T) {
+		tables, err := parseDDL("CREATE TABLE my-proj-1.ds.t (id INT64); CREATE TABLE `other-proj`.ds.u (id INT64)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tables) != 2 || tables[0].tableID != "t" || tables[0].md.FullID != "my-proj-1:ds.t" || tables[1].md.FullID != "other-proj:ds.u" {
+			t.Errorf("parseDDL: tables=%#v", tables)
+		}
+	})
+
+	t.Run("異常系_ALTER_TABLE", func(t *testing.T) {
+		for _, src := range []string{
+			"CREATE TABLE t (a INT64); ALTER TABLE t ADD COLUMN A STRING",
+			"CREATE TABLE t (a INT64); ALTER TABLE t DROP COLUMN b",
+			"CREATE TABLE t (a INT64); ALTER TABLE t RENAME COLUMN b TO c",
+			"CREATE TABLE t (a INT64); ALTER TABLE t ALTER COLUMN a SET UNKNOWN",
+			"CREATE TABLE t (a INT64); ALTER TABLE t ADD COLUMN b STRING c",
+		} {
+			if _, err := parseDDL(src); err == nil {
+				t.Errorf("parseDDL: src=%s", src)
+			}
+		}
+	})
+
+	t.Run("異常系_error_position", func(t *testing.T) {
+		_, err := parseDDL("CREATE TABLE t (\n  a INT64,\n  b UNKNOWNTYPE\n)")
+		if err == nil || !strings.Contains(err.Error(), "3:5") || !strings.Contains(err.Error(), "UNKNOWNTYPE") {
+			t.Errorf("parseDDL: err=%v", err)
+		}
+	})
+
+	t.Run("異常系_unterminated", func(t *testing.T) {
+		for _, src := range []string{
+			"CREATE TABLE t (a STRING OPTIONS(description='x))",
+			"CREATE TABLE t (a STRUCT<b INT64)",
+			"CREATE TABLE t (a INT64 /* comment",
+		} {
+			if _, err := parseDDL(src); err == nil {
+				t.Errorf("parseDDL: src=%s", src)
+			}
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package bqschemagen
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TableError is the reason why a table is not generated.
+type TableError struct {
+	// TableID is the table ID qualified by the dataset ID if it is known (e.g. `sales.users`).
+	TableID string
+	Err     error
+}
+
+func (e *TableError) Error() string {
+	return e.TableID + ": " + e.Err.Error()
+}
+
+func (e *TableError) Unwrap() error {
+	return e.Err
+}
+
+// TableErrors is the error of Generate with Options.Strict, which reports all tables that are not generated.
+type TableErrors []*TableError
+
+func (errs TableErrors) Error() string {
+	report := strconv.Itoa(len(errs)) + " tables can not be generated:"
+	for _, e := range errs {
+		report = report + "\n\t" + strings.ReplaceAll(e.Error(), "\n", "\n\t\t")
+	}
+	return report
+}
+
+// Unwrap returns the errors of the tables for errors.Is and errors.As.
+func (errs TableErrors) Unwrap() []error {
+	unwrapped := make([]error, len(errs))
+	for i, e := range errs {
+		unwrapped[i] = e
+	}
+	return unwrapped
+}
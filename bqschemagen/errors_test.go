@@ -0,0 +1,87 @@
+package bqschemagen
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/googleapi"
+)
+
+func Test_TableErrors_Error(t *testing.T) {
+	const (
+		// 正しい出力
+		testReport = "2 tables can not be generated:\n" +
+			"\tsales.users: readTable: table not found\n" +
+			"\tsales.events: generateTableSchemaCode: line 1\n" +
+			"\t\tline 2"
+	)
+
+	errs := TableErrors{
+		{TableID: "sales.users", Err: errors.New("readTable: table not found")},
+		{TableID: "sales.events", Err: errors.New("generateTableSchemaCode: line 1\nline 2")},
+	}
+	if report := errs.Error(); report != testReport {
+		t.Error("Error: want=`" + testReport + "` current=`" + report + "`")
+	}
+}
+
+func Test_Generator_Generate_Strict(t *testing.T) {
+	setTestBackoff(t)
+
+	newSource := func() SchemaSource {
+		return NewMemorySource(
+			&TableSchema{TableID: "ok", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}},
+			&TableSchema{TableID: "ng_type", Fields: []*FieldSchema{{Name: "id", Type: testNotSupportedFieldType}}},
+			&TableSchema{TableID: "ng_empty"},
+		)
+	}
+
+	t.Run("正常系_not_Strict", func(t *testing.T) {
+		if _, err := newTestGenerator(t, Options{}).Generate(context.Background(), newSource()); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("異常系_Strict", func(t *testing.T) {
+		_, err := newTestGenerator(t, Options{Strict: true}).Generate(context.Background(), newSource())
+
+		var errs TableErrors
+		if !errors.As(err, &errs) {
+			t.Fatal(err)
+		}
+		if len(errs) != 2 || errs[0].TableID != "ng_empty" || errs[1].TableID != "ng_type" {
+			t.Errorf("Generate: %v", err)
+		}
+	})
+
+	t.Run("異常系_Strict_ErrRetriesExhausted", func(t *testing.T) {
+		source := &failingSource{SchemaSource: NewMemorySource(
+			&TableSchema{TableID: "users", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}},
+			&TableSchema{TableID: "orders", Fields: []*FieldSchema{{Name: "id", Type: testNotSupportedFieldType}}},
+		), errs: map[string]error{
+			"users": &googleapi.Error{Code: http.StatusServiceUnavailable},
+		}}
+		_, err := newTestGenerator(t, Options{Strict: true, MaxAttempts: 2}).Generate(context.Background(), source)
+
+		var errs TableErrors
+		if !errors.As(err, &errs) || len(errs) != 2 || !errors.Is(err, ErrRetriesExhausted) {
+			t.Errorf("Generate: %v", err)
+		}
+	})
+}
+
+// failingSource is the SchemaSource that always fails to read the tables of errs.
+type failingSource struct {
+	SchemaSource
+	errs map[string]error
+}
+
+func (s *failingSource) Table(ctx context.Context, tableID string) (table *TableSchema, err error) {
+	if err, ok := s.errs[tableID]; ok {
+		return nil, err
+	}
+	return s.SchemaSource.Table(ctx, tableID)
+}
@@ -0,0 +1,64 @@
+package bqschemagen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// defaultConcurrency is the default of Options.Concurrency.
+const defaultConcurrency = 8
+
+// fetchJob is a table schema to read from a source.
+type fetchJob struct {
+	source  SchemaSource
+	tableID string
+}
+
+// fetchResult is the schema or the error of a fetchJob.
+type fetchResult struct {
+	table *TableSchema
+	err   error
+}
+
+// fetchTables reads the schemas of jobs by Options.Concurrency workers through r, and returns the results in the order of jobs.
+// The errors of the jobs are in the results, but fetchTables fails if ctx is done before all jobs are read.
+func (g *Generator) fetchTables(ctx context.Context, r *requester, jobs []fetchJob) (results []fetchResult, err error) {
+	results = make([]fetchResult, len(jobs))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < g.opts.Concurrency && worker < len(jobs); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				// NOTE(ginokent): each worker writes only the results of its own indexes, so the results need no lock.
+				var table *TableSchema
+				err := r.do(ctx, func() (err error) {
+					table, err = jobs[i].source.Table(ctx, jobs[i].tableID)
+					return err
+				})
+				results[i] = fetchResult{table: table, err: err}
+			}
+		}()
+	}
+
+	func() {
+		defer close(indexes)
+		for i := range jobs {
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	if err = ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ctx.Err: %w", err)
+	}
+
+	return results, nil
+}
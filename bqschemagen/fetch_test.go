@@ -0,0 +1,128 @@
+package bqschemagen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"golang.org/x/time/rate"
+)
+
+// slowSource is the SchemaSource that takes delay to read a table, and records the maximum number of the concurrent reads.
+type slowSource struct {
+	SchemaSource
+	delay time.Duration
+
+	mu            sync.Mutex
+	inFlight      int
+	maxInFlight   int
+	errorTableIDs map[string]bool
+}
+
+func (s *slowSource) Table(ctx context.Context, tableID string) (table *TableSchema, err error) {
+	s.mu.Lock()
+	s.inFlight++
+	if s.inFlight > s.maxInFlight {
+		s.maxInFlight = s.inFlight
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if s.errorTableIDs[tableID] {
+		return nil, errors.New("test error")
+	}
+	return s.SchemaSource.Table(ctx, tableID)
+}
+
+func newSlowSource(n int, delay time.Duration) *slowSource {
+	tables := make([]*TableSchema, n)
+	for i := range tables {
+		tables[i] = &TableSchema{TableID: fmt.Sprintf("table_%03d", i), Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}}
+	}
+	return &slowSource{SchemaSource: NewMemorySource(tables...), delay: delay, errorTableIDs: make(map[string]bool)}
+}
+
+func Test_Generator_fetchTables(t *testing.T) {
+	t.Run("正常系_Concurrency", func(t *testing.T) {
+		source := newSlowSource(20, 10*time.Millisecond)
+		source.errorTableIDs["table_003"] = true
+		source.errorTableIDs["table_017"] = true
+
+		var jobs []fetchJob
+		for i := 0; i < 20; i++ {
+			jobs = append(jobs, fetchJob{source: source, tableID: fmt.Sprintf("table_%03d", i)})
+		}
+
+		results, err := newTestGenerator(t, Options{Concurrency: 4}).fetchTables(context.Background(), &requester{limiter: rate.NewLimiter(rate.Inf, 1), maxAttempts: 1, logger: log.Default()}, jobs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, result := range results {
+			switch {
+			case source.errorTableIDs[jobs[i].tableID]:
+				if result.err == nil {
+					t.Errorf("fetchTables: tableID=%s want error", jobs[i].tableID)
+				}
+			case result.err != nil || result.table.TableID != jobs[i].tableID:
+				t.Errorf("fetchTables: tableID=%s result=%#v", jobs[i].tableID, result)
+			}
+		}
+		if source.maxInFlight > 4 || source.maxInFlight < 2 {
+			t.Errorf("fetchTables: maxInFlight=%d", source.maxInFlight)
+		}
+	})
+
+	t.Run("異常系_context_canceled", func(t *testing.T) {
+		source := newSlowSource(20, time.Second)
+
+		var jobs []fetchJob
+		for i := 0; i < 20; i++ {
+			jobs = append(jobs, fetchJob{source: source, tableID: fmt.Sprintf("table_%03d", i)})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if _, err := newTestGenerator(t, Options{Concurrency: 2}).fetchTables(ctx, &requester{limiter: rate.NewLimiter(rate.Inf, 1), maxAttempts: 1, logger: log.Default()}, jobs); !errors.Is(err, context.DeadlineExceeded) {
+			t.Error(err)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("fetchTables: cancellation is not honored: elapsed=%s", elapsed)
+		}
+	})
+}
+
+func Test_Generator_Generate_Concurrency(t *testing.T) {
+	var want string
+	for _, concurrency := range []int{1, 3, 16} {
+		generatedCode, err := newTestGenerator(t, Options{Concurrency: concurrency}).Generate(context.Background(), newSlowSource(30, time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want == "" {
+			want = string(generatedCode)
+			if strings.Index(want, "type Table000 struct") > strings.Index(want, "type Table029 struct") {
+				t.Error("Generate: tables are not sorted: " + want)
+			}
+			continue
+		}
+		if string(generatedCode) != want {
+			t.Errorf("Generate: Concurrency=%d is not deterministic: want=`%s` current=`%s`", concurrency, want, generatedCode)
+		}
+	}
+}
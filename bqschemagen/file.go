@@ -0,0 +1,95 @@
+package bqschemagen
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// GeneratedFileSuffix is the suffix of the file names of GenerateFiles.
+const GeneratedFileSuffix = ".generated.go"
+
+// GenerateFiles generates the code for the tables in source like Generate, but into a file per table of the same package.
+// The files are keyed by the file name `<table>.generated.go`, and only the first file has the go:generate directive.
+// The errors are handled like Generate.
+func (g *Generator) GenerateFiles(ctx context.Context, source SchemaSource) (files map[string][]byte, err error) {
+	datasetTables, err := g.generateTables(ctx, []Dataset{{Source: source}}, []string{""}, false)
+	if err != nil {
+		return nil, fmt.Errorf("generateTables: %w", err)
+	}
+	return g.generateTableFiles(g.opts.PackageName, datasetTables[0])
+}
+
+// GeneratePackageFiles generates the code for the tables of each dataset in datasets like GeneratePackages,
+// but into a file per table of the package like GenerateFiles. packageFiles[i] are the files of datasets[i].
+func (g *Generator) GeneratePackageFiles(ctx context.Context, datasets []Dataset, packageNames []string) (packageFiles []map[string][]byte, err error) {
+	datasetTables, err := g.generatePackageTables(ctx, datasets, packageNames)
+	if err != nil {
+		return nil, fmt.Errorf("generatePackageTables: %w", err)
+	}
+
+	packageFiles = make([]map[string][]byte, len(datasets))
+	for i, tables := range datasetTables {
+		packageFiles[i], err = g.generateTableFiles(packageNames[i], tables)
+		if err != nil {
+			return nil, fmt.Errorf("generateTableFiles: datasetID=%s, %w", datasets[i].DatasetID, err)
+		}
+	}
+	return packageFiles, nil
+}
+
+// generateTableFiles generates a file per table of the package packageName for tables, keyed by the file name.
+func (g *Generator) generateTableFiles(packageName string, tables []*TableData) (files map[string][]byte, err error) {
+	files = make(map[string][]byte, len(tables))
+	// NOTE(ginokent): the file names are compared case-insensitively, because `Users` and `users` are the same file on macOS and Windows.
+	fileTableIDs := make(map[string]string, len(tables))
+	for i, table := range tables {
+		fileName := tableFileName(table.Table.TableID)
+		if tableID, ok := fileTableIDs[strings.ToLower(fileName)]; ok {
+			return nil, fmt.Errorf("%w: file %s of tables %s and %s", ErrIdentifierCollision, fileName, tableID, table.Table.TableID)
+		}
+		fileTableIDs[strings.ToLower(fileName)] = table.Table.TableID
+
+		files[fileName], err = g.generateFile(packageName, tables[i:i+1], i == 0)
+		if err != nil {
+			return nil, fmt.Errorf("generateFile: tableID=%s, %w", table.Table.TableID, err)
+		}
+	}
+
+	return files, nil
+}
+
+// goFileNameSuffixes are the `_*` suffixes of a file name that the go command reads as the build constraints or the test files.
+// NOTE(ginokent): ref. https://github.com/golang/go/blob/go1.26.0/src/internal/syslist/syslist.go
+var goFileNameSuffixes = map[string]bool{
+	"test": true,
+	// GOOS
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true, "illumos": true, "ios": true, "js": true,
+	"linux": true, "nacl": true, "netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "wasip1": true, "windows": true, "zos": true,
+	// GOARCH
+	"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true, "arm64be": true, "loong64": true,
+	"mips": true, "mipsle": true, "mips64": true, "mips64le": true, "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true, "ppc64le": true,
+	"riscv": true, "riscv64": true, "s390": true, "s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+}
+
+// tableFileName returns the file name of GenerateFiles for tableID.
+// The characters other than letters, digits, `_` and `-` are replaced with `_`,
+// and the name that starts with `_` is prefixed with `x`, because the go command ignores the files that start with `_` or `.`.
+// The name that ends with a GOOS, GOARCH or `_test` suffix (e.g. `events_windows`) is suffixed with `_`,
+// because the go command reads the part before the first `.` as the build constraints, which exclude the file on the other platforms.
+func tableFileName(tableID string) (fileName string) {
+	fileName = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, tableID)
+	if strings.HasPrefix(fileName, "_") {
+		fileName = "x" + fileName
+	}
+	// NOTE(ginokent): ref. https://pkg.go.dev/cmd/go#hdr-Build_constraints
+	if words := strings.Split(fileName, "_"); len(words) > 1 && goFileNameSuffixes[words[len(words)-1]] {
+		fileName += "_"
+	}
+	return fileName + GeneratedFileSuffix
+}
@@ -0,0 +1,107 @@
+package bqschemagen
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func Test_tableFileName(t *testing.T) {
+	for tableID, want := range map[string]string{
+		"users":                "users.generated.go",
+		"Events-2024":          "Events-2024.generated.go",
+		"_tmp":                 "x_tmp.generated.go",
+		"売上":                   "x__.generated.go",
+		"events_test":          "events_test_.generated.go",
+		"events_linux":         "events_linux_.generated.go",
+		"events_windows_amd64": "events_windows_amd64_.generated.go",
+		"events_linux_test":    "events_linux_test_.generated.go",
+		"events_arm64":         "events_arm64_.generated.go",
+		"linux":                "linux.generated.go",
+		"events_Linux":         "events_Linux.generated.go",
+		"events_linuxes":       "events_linuxes.generated.go",
+	} {
+		if fileName := tableFileName(tableID); fileName != want {
+			t.Error("tableFileName: want=`" + want + "` current=`" + fileName + "`")
+		}
+	}
+}
+
+func Test_Generator_GenerateFiles(t *testing.T) {
+	const (
+		// 正しい出力
+		testEventsFile = `// Code generated by go run github.com/ginokent/bqschema-gen-go; DO NOT EDIT.
+
+//go:generate go run github.com/ginokent/bqschema-gen-go
+
+package warehouse
+
+import "time"
+
+// Events is BigQuery Table ` + "`project:dataset.events`" + ` schema struct.
+// Description: events
+type Events struct {
+	ID        int64     ` + "`bigquery:\"id\"`" + `
+	CreatedAt time.Time ` + "`bigquery:\"created_at\"`" + `
+}
+`
+		testUsersFile = `// Code generated by go run github.com/ginokent/bqschema-gen-go; DO NOT EDIT.
+
+package warehouse
+
+// Users is BigQuery Table ` + "`project:dataset.users`" + ` schema struct.
+// Description: users
+type Users struct {
+	ID int64 ` + "`bigquery:\"id\"`" + `
+}
+`
+	)
+
+	t.Run("正常系", func(t *testing.T) {
+		source := NewMemorySource(
+			&TableSchema{TableID: "users", FullID: "project:dataset.users", Description: "users", Fields: []*FieldSchema{
+				{Name: "id", Type: bigquery.IntegerFieldType},
+			}},
+			&TableSchema{TableID: "events", FullID: "project:dataset.events", Description: "events", Fields: []*FieldSchema{
+				{Name: "id", Type: bigquery.IntegerFieldType},
+				{Name: "created_at", Type: bigquery.TimestampFieldType},
+			}},
+		)
+
+		files, err := newTestGenerator(t, Options{PackageName: "warehouse"}).GenerateFiles(context.Background(), source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fileNames []string
+		for fileName := range files {
+			fileNames = append(fileNames, fileName)
+		}
+		sort.Strings(fileNames)
+		if want := []string{"events.generated.go", "users.generated.go"}; !reflect.DeepEqual(fileNames, want) {
+			t.Errorf("GenerateFiles: want=%v current=%v", want, fileNames)
+		}
+		if code := string(files["events.generated.go"]); code != testEventsFile {
+			t.Error("GenerateFiles: want=`" + testEventsFile + "` current=`" + code + "`")
+		}
+		if code := string(files["users.generated.go"]); code != testUsersFile {
+			t.Error("GenerateFiles: want=`" + testUsersFile + "` current=`" + code + "`")
+		}
+	})
+
+	t.Run("異常系_file_name_collision", func(t *testing.T) {
+		source := NewMemorySource(
+			&TableSchema{TableID: "Users", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}},
+			&TableSchema{TableID: "users", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}},
+		)
+
+		_, err := newTestGenerator(t, Options{}).GenerateFiles(context.Background(), source)
+		if !errors.Is(err, ErrIdentifierCollision) || !strings.Contains(err.Error(), "tables Users and users") {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package bqschemagen
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RegexpPatternPrefix is the prefix of the table ID patterns that are regular expressions instead of globs.
+const RegexpPatternPrefix = "re:"
+
+// tablePattern matches the table IDs by a glob of path.Match, or by a regular expression if the pattern has RegexpPatternPrefix.
+type tablePattern struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+func newTablePattern(pattern string) (p tablePattern, err error) {
+	if strings.TrimSpace(pattern) == "" {
+		return tablePattern{}, fmt.Errorf("pattern is empty")
+	}
+
+	if strings.HasPrefix(pattern, RegexpPatternPrefix) {
+		var re *regexp.Regexp
+		re, err = regexp.Compile(strings.TrimPrefix(pattern, RegexpPatternPrefix))
+		if err != nil {
+			return tablePattern{}, fmt.Errorf("regexp.Compile: %w", err)
+		}
+		return tablePattern{pattern: pattern, re: re}, nil
+	}
+
+	// NOTE(ginokent): path.Match only reports the syntax errors when the pattern is matched against.
+	if _, err = path.Match(pattern, ""); err != nil {
+		return tablePattern{}, fmt.Errorf("path.Match: pattern=%s, %w", pattern, err)
+	}
+	return tablePattern{pattern: pattern}, nil
+}
+
+func (p tablePattern) match(tableID string) bool {
+	if p.re != nil {
+		return p.re.MatchString(tableID)
+	}
+	matched, _ := path.Match(p.pattern, tableID)
+	return matched
+}
+
+// tableFilter selects the tables to generate by the table IDs, before the schemas are read.
+type tableFilter struct {
+	include []tablePattern
+	exclude []tablePattern
+}
+
+// newTableFilter returns the tableFilter of the include and exclude patterns.
+func newTableFilter(include, exclude []string) (filter *tableFilter, err error) {
+	filter = &tableFilter{}
+	for _, pattern := range include {
+		var p tablePattern
+		p, err = newTablePattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("include: %w", err)
+		}
+		filter.include = append(filter.include, p)
+	}
+	for _, pattern := range exclude {
+		var p tablePattern
+		p, err = newTablePattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("exclude: %w", err)
+		}
+		filter.exclude = append(filter.exclude, p)
+	}
+	return filter, nil
+}
+
+// match reports whether tableID matches any include pattern, or there is none, and matches no exclude pattern.
+func (f *tableFilter) match(tableID string) bool {
+	included := len(f.include) == 0
+	for _, p := range f.include {
+		if p.match(tableID) {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+
+	for _, p := range f.exclude {
+		if p.match(tableID) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterTableIDs splits tableIDs into the ones that Options.Include and Options.Exclude select and the others.
+func (g *Generator) FilterTableIDs(tableIDs []string) (included, excluded []string) {
+	for _, tableID := range tableIDs {
+		if g.filter.match(tableID) {
+			included = append(included, tableID)
+			continue
+		}
+		excluded = append(excluded, tableID)
+	}
+	return included, excluded
+}
@@ -0,0 +1,134 @@
+package bqschemagen
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func Test_tablePattern_match(t *testing.T) {
+	for _, tt := range []struct {
+		pattern string
+		tableID string
+		want    bool
+	}{
+		{"tmp_*", "tmp_users", true},
+		{"tmp_*", "users_tmp", false},
+		{"events_2020????", "events_20200101", true},
+		{"events_2020????", "events_2020", false},
+		{"users", "users", true},
+		{"users", "users_backup", false},
+		{"re:_backup$", "users_backup", true},
+		{"re:_backup$", "users_backup_v2", false},
+		{"re:^(users|orders)$", "orders", true},
+		{"re:^(users|orders)$", "orders_2020", false},
+		{"re:scratch", "my_scratch_table", true},
+	} {
+		p, err := newTablePattern(tt.pattern)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if matched := p.match(tt.tableID); matched != tt.want {
+			t.Errorf("match: pattern=%s tableID=%s want=%t current=%t", tt.pattern, tt.tableID, tt.want, matched)
+		}
+	}
+
+	for _, pattern := range []string{"", " ", "re:(", "[a-"} {
+		if _, err := newTablePattern(pattern); err == nil {
+			t.Error("newTablePattern: pattern=`" + pattern + "` want error")
+		}
+	}
+}
+
+func Test_Generator_FilterTableIDs(t *testing.T) {
+	tableIDs := []string{"events_20200101", "events_20200102", "orders", "tmp_orders", "users", "users_backup"}
+
+	for _, tt := range []struct {
+		name     string
+		opts     Options
+		included []string
+	}{
+		{"正常系_no_filter", Options{}, tableIDs},
+		{"正常系_Include", Options{Include: []string{"events_*", "users"}}, []string{"events_20200101", "events_20200102", "users"}},
+		{"正常系_Exclude", Options{Exclude: []string{"tmp_*", "re:_backup$"}}, []string{"events_20200101", "events_20200102", "orders", "users"}},
+		{"正常系_Include_Exclude", Options{Include: []string{"re:orders"}, Exclude: []string{"tmp_*"}}, []string{"orders"}},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			included, excluded := newTestGenerator(t, tt.opts).FilterTableIDs(tableIDs)
+			if !reflect.DeepEqual(included, tt.included) {
+				t.Errorf("FilterTableIDs: want=%v current=%v", tt.included, included)
+			}
+			if len(included)+len(excluded) != len(tableIDs) {
+				t.Errorf("FilterTableIDs: included=%v excluded=%v", included, excluded)
+			}
+		})
+	}
+
+	t.Run("異常系_invalid_pattern", func(t *testing.T) {
+		if _, err := New(Options{Exclude: []string{"re:["}}); err == nil {
+			t.Error(err)
+		}
+	})
+}
+
+// countingSource is the SchemaSource that counts the TableIDs and Table calls, which are the API calls of the BigQuery API.
+type countingSource struct {
+	SchemaSource
+	mu            sync.Mutex
+	tableIDs      []string
+	tableIDsCalls int
+}
+
+func (s *countingSource) TableIDs(ctx context.Context) (tableIDs []string, err error) {
+	s.mu.Lock()
+	s.tableIDsCalls++
+	s.mu.Unlock()
+	return s.SchemaSource.TableIDs(ctx)
+}
+
+func (s *countingSource) Table(ctx context.Context, tableID string) (table *TableSchema, err error) {
+	s.mu.Lock()
+	s.tableIDs = append(s.tableIDs, tableID)
+	s.mu.Unlock()
+	return s.SchemaSource.Table(ctx, tableID)
+}
+
+func Test_Generator_Generate_filter(t *testing.T) {
+	source := &countingSource{SchemaSource: NewMemorySource(
+		&TableSchema{TableID: "users", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}},
+		&TableSchema{TableID: "tmp_users", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}},
+		&TableSchema{TableID: "users_backup", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}},
+	)}
+
+	generatedCode, err := newTestGenerator(t, Options{Exclude: []string{"tmp_*", "re:_backup$"}}).Generate(context.Background(), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(generatedCode), "type Users struct") || strings.Contains(string(generatedCode), "TmpUsers") || strings.Contains(string(generatedCode), "UsersBackup") {
+		t.Error("Generate: " + string(generatedCode))
+	}
+	if want := []string{"users"}; !reflect.DeepEqual(source.tableIDs, want) {
+		t.Errorf("Generate: Table is called for the excluded tables: want=%v current=%v", want, source.tableIDs)
+	}
+}
+
+func Test_generateSummary(t *testing.T) {
+	const (
+		// 正しい出力
+		testSummary      = "generated 2 tables, skipped 2 tables by filter (sales.tmp_users, sales.users_backup), skipped 1 tables by error (sales.ng)"
+		testSummaryEmpty = "generated 0 tables, skipped 0 tables by filter, skipped 0 tables by error"
+	)
+
+	if summary := generateSummary(2, []string{"sales.tmp_users", "sales.users_backup"}, []string{"sales.ng"}); summary != testSummary {
+		t.Error("generateSummary: want=`" + testSummary + "` current=`" + summary + "`")
+	}
+	if summary := generateSummary(0, nil, nil); summary != testSummaryEmpty {
+		t.Error("generateSummary: want=`" + testSummaryEmpty + "` current=`" + summary + "`")
+	}
+}
@@ -0,0 +1,198 @@
+package bqschemagen
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultInitialisms are the initialisms upper-cased by NamingModeCamel by default.
+// NOTE(ginokent): ref. https://github.com/golang/lint/blob/6edffad5e6160f5949cdefc81710b2706fbcd4f6/lint.go#L770-L809
+var DefaultInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID",
+	"IP", "JSON", "LHS", "QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH", "TCP",
+	"TLS", "TTL", "UDP", "UI", "UID", "UUID", "URI", "URL", "UTF8", "VM", "XML", "XMPP",
+	"XSRF", "XSS",
+}
+
+// ErrIdentifierCollision is returned when two names map to the same Go identifier and Options.Collision is CollisionError.
+var ErrIdentifierCollision = errors.New("go identifier collision")
+
+// newInitialisms returns the set of upper-cased initialisms.
+func newInitialisms(words []string) (initialisms map[string]bool) {
+	initialisms = make(map[string]bool, len(words))
+	for _, word := range words {
+		if word = strings.ToUpper(strings.TrimSpace(word)); word != "" {
+			initialisms[word] = true
+		}
+	}
+	return initialisms
+}
+
+// toGoName converts a BigQuery table or column name into an exported Go identifier following Options.NamingMode.
+// In NamingModeCamel, `snake_case`, `kebab-case`, `space separated` and `camelCase` names become `CamelCase`,
+// and the words in initialisms are upper-cased (e.g. `user_id` -> `UserID`, `time_ts` -> `TimeTs`).
+// In NamingModeLegacy, only the initial is upper-cased (e.g. `user_id` -> `User_id`).
+// The result is sanitized by sanitizeGoName, with a warning if name can not be converted as is.
+func (g *Generator) toGoName(name string) (goName string) {
+	if g.opts.NamingMode == NamingModeLegacy {
+		goName = capitalizeInitial(name)
+	} else {
+		goName = g.camelCase(name)
+	}
+
+	sanitized := sanitizeGoName(goName)
+	if sanitized != goName || strings.IndexFunc(name, func(r rune) bool { return !isIdentifierRune(r) && !isWordSeparator(r) }) >= 0 {
+		warnln(g.opts.Logger, fmt.Sprintf("name `%s` is not a valid Go identifier. replacing `%s` to `%s`", name, name, sanitized))
+	}
+
+	return sanitized
+}
+
+// camelCase joins the words of name in CamelCase, upper-casing the words in Options.Initialisms.
+func (g *Generator) camelCase(name string) (goName string) {
+	for _, word := range splitWords(name) {
+		upper := strings.ToUpper(word)
+		if g.initialisms[upper] {
+			goName = goName + upper
+			continue
+		}
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		goName = goName + string(runes)
+	}
+
+	return goName
+}
+
+// lowerCamelCase joins the words of name in lowerCamelCase like camelCase, lower-casing the first word (e.g. `user_id` -> `userID`, `ID` -> `id`).
+func (g *Generator) lowerCamelCase(name string) (lowerCamel string) {
+	for i, word := range splitWords(name) {
+		upper := strings.ToUpper(word)
+		switch {
+		case i == 0:
+			lowerCamel = strings.ToLower(word)
+		case g.initialisms[upper]:
+			lowerCamel = lowerCamel + upper
+		default:
+			runes := []rune(strings.ToLower(word))
+			runes[0] = unicode.ToUpper(runes[0])
+			lowerCamel = lowerCamel + string(runes)
+		}
+	}
+	return lowerCamel
+}
+
+// snakeCase joins the lower-cased words of name with `_` (e.g. `userId` -> `user_id`, `HTTPServer` -> `http_server`).
+func snakeCase(name string) (snake string) {
+	words := splitWords(name)
+	for i := range words {
+		words[i] = strings.ToLower(words[i])
+	}
+	return strings.Join(words, "_")
+}
+
+// sanitizeGoName makes goName a valid exported Go identifier.
+// The runes that are invalid in Go identifiers are replaced with `_`, and `X` is prepended if goName does not start with an upper-case letter
+// (e.g. `1st` -> `X1st`, `日付` -> `X日付`, “ -> `X`).
+// Because the result is exported, it never collides with Go keywords (e.g. `type`) or predeclared identifiers.
+func sanitizeGoName(goName string) (sanitized string) {
+	sanitized = strings.Map(func(r rune) rune {
+		if isIdentifierRune(r) {
+			return r
+		}
+		return '_'
+	}, goName)
+
+	if first, _ := utf8.DecodeRuneInString(sanitized); !unicode.IsUpper(first) {
+		sanitized = "X" + sanitized
+	}
+
+	return sanitized
+}
+
+// splitWords splits name into words at the runes that are invalid in Go identifiers, `_` and camelCase boundaries.
+// Digits belong to the preceding word (e.g. `utf8_name` -> `utf8`, `name`).
+func splitWords(name string) (words []string) {
+	runes := []rune(name)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		if i == len(runes) || runes[i] == '_' || !isIdentifierRune(runes[i]) {
+			if start < i {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		// NOTE(ginokent): `userId` -> `user`, `Id` and `HTTPServer` -> `HTTP`, `Server`
+		prev := runes[i-1]
+		nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return words
+}
+
+// isWordSeparator reports whether r is one of the word separators commonly used in BigQuery names.
+func isWordSeparator(r rune) bool {
+	return r == '_' || r == '-' || r == '.' || r == ' '
+}
+
+// NOTE(ginokent): ref. https://go.dev/ref/spec#Identifiers
+func isIdentifierRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// identifierScope tracks the Go identifiers declared in a scope, such as the package-level types or the fields of a struct.
+type identifierScope struct {
+	name      string
+	collision CollisionStrategy
+	logger    *log.Logger
+	// declared maps a declared Go identifier to the original name.
+	declared map[string]string
+}
+
+// newIdentifierScope returns the empty scope named name, which resolves the collisions following Options.Collision.
+func (g *Generator) newIdentifierScope(name string) *identifierScope {
+	return &identifierScope{
+		name:      name,
+		collision: g.opts.Collision,
+		logger:    g.opts.Logger,
+		declared:  make(map[string]string),
+	}
+}
+
+// declare declares goName converted from original in the scope.
+// If goName is already declared, it is resolved following the collision strategy of the scope:
+// CollisionSuffix appends the smallest free suffix `_2`, `_3`, ..., and CollisionError returns ErrIdentifierCollision.
+func (s *identifierScope) declare(goName, original string) (declared string, err error) {
+	conflicting, ok := s.declared[goName]
+	if !ok {
+		s.declared[goName] = original
+		return goName, nil
+	}
+
+	if s.collision == CollisionError {
+		return "", fmt.Errorf("%w: %s: `%s` and `%s` both map to `%s`", ErrIdentifierCollision, s.name, conflicting, original, goName)
+	}
+
+	for i := 2; ; i++ {
+		declared = goName + "_" + strconv.Itoa(i)
+		if _, ok := s.declared[declared]; !ok {
+			break
+		}
+	}
+	warnln(s.logger, fmt.Sprintf("%s: `%s` and `%s` both map to `%s`. replacing `%s` to `%s`", s.name, conflicting, original, goName, original, declared))
+	s.declared[declared] = original
+
+	return declared, nil
+}
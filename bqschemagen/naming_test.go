@@ -0,0 +1,247 @@
+package bqschemagen
+
+import (
+	"errors"
+	"go/format"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func Test_toGoName(t *testing.T) {
+	t.Run("正常系_NamingModeCamel", func(t *testing.T) {
+		g := newTestGenerator(t, Options{})
+		for name, want := range map[string]string{
+			"id":             "ID",
+			"url":            "URL",
+			"time_ts":        "TimeTs",
+			"full_201510":    "Full201510",
+			"user_id":        "UserID",
+			"userId":         "UserID",
+			"events-raw":     "EventsRaw",
+			"first name":     "FirstName",
+			"HTTPServer":     "HTTPServer",
+			"TOTAL_AMOUNT":   "TotalAmount",
+			"utf8_string":    "UTF8String",
+			"request_uuids":  "RequestUuids",
+			"Already":        "Already",
+			"__leading_name": "LeadingName",
+		} {
+			if goName := g.toGoName(name); goName != want {
+				t.Error("toGoName: name=" + name + " want=" + want + " current=" + goName)
+			}
+		}
+	})
+
+	t.Run("正常系_initialisms", func(t *testing.T) {
+		g := newTestGenerator(t, Options{Initialisms: []string{"ts", " Uuid "}})
+
+		for name, want := range map[string]string{
+			"time_ts":    "TimeTS",
+			"id":         "Id",
+			"event_uuid": "EventUUID",
+		} {
+			if goName := g.toGoName(name); goName != want {
+				t.Error("toGoName: name=" + name + " want=" + want + " current=" + goName)
+			}
+		}
+	})
+
+	t.Run("正常系_NamingModeLegacy", func(t *testing.T) {
+		g := newTestGenerator(t, Options{NamingMode: NamingModeLegacy})
+
+		for name, want := range map[string]string{
+			"id":          "Id",
+			"time_ts":     "Time_ts",
+			"full_201510": "Full_201510",
+		} {
+			if goName := g.toGoName(name); goName != want {
+				t.Error("toGoName: name=" + name + " want=" + want + " current=" + goName)
+			}
+		}
+	})
+}
+
+func Test_sanitizeGoName(t *testing.T) {
+	for goName, want := range map[string]string{
+		"UserID":     "UserID",
+		"":           "X",
+		"1st":        "X1st",
+		"日付":         "X日付",
+		"Time-ts":    "Time_ts",
+		"_id":        "X_id",
+		"Price($)":   "Price___",
+		"Größe":      "Größe",
+		"Événement":  "Événement",
+		"type":       "Xtype",
+		"Func Range": "Func_Range",
+	} {
+		if sanitized := sanitizeGoName(goName); sanitized != want {
+			t.Error("sanitizeGoName: goName=" + goName + " want=" + want + " current=" + sanitized)
+		}
+	}
+}
+
+func Test_toGoName_sanitize(t *testing.T) {
+	t.Run("正常系_NamingModeCamel", func(t *testing.T) {
+		g := newTestGenerator(t, Options{})
+		for name, want := range map[string]string{
+			"2020_sales": "X2020Sales",
+			"type":       "Type",
+			"func":       "Func",
+			"range":      "Range",
+			"price($)":   "Price",
+			"日付":         "X日付",
+			"___":        "X",
+			"café_name":  "CaféName",
+		} {
+			if goName := g.toGoName(name); goName != want {
+				t.Error("toGoName: name=" + name + " want=" + want + " current=" + goName)
+			}
+		}
+	})
+
+	t.Run("正常系_NamingModeLegacy", func(t *testing.T) {
+		g := newTestGenerator(t, Options{NamingMode: NamingModeLegacy})
+
+		for name, want := range map[string]string{
+			"events-raw": "Events_raw",
+			"2020_sales": "X2020_sales",
+			"type":       "Type",
+			"_id":        "X_id",
+		} {
+			if goName := g.toGoName(name); goName != want {
+				t.Error("toGoName: name=" + name + " want=" + want + " current=" + goName)
+			}
+		}
+	})
+
+	t.Run("正常系_format.Source", func(t *testing.T) {
+		var (
+			testSchema = []*FieldSchema{
+				{Name: "type", Type: bigquery.StringFieldType},
+				{Name: "func", Type: bigquery.StringFieldType},
+				{Name: "range", Type: bigquery.StringFieldType},
+				{Name: "1st_place", Type: bigquery.StringFieldType},
+				{Name: "日付", Type: bigquery.StringFieldType},
+				{Name: "amount (usd)", Type: bigquery.StringFieldType},
+			}
+		)
+
+		for _, mode := range []NamingMode{NamingModeCamel, NamingModeLegacy} {
+			g := newTestGenerator(t, Options{NamingMode: mode})
+
+			generatedCode, _, err := generateTestStructCode(g, g.toGoName("1-weird table"), "1-weird table", testSchema, g.newIdentifierScope("package"))
+			if err != nil {
+				t.Error(err)
+			}
+			if _, err := format.Source([]byte("package bqschema\n\n" + generatedCode)); err != nil {
+				t.Error("format.Source: NamingMode=" + string(mode) + " " + err.Error())
+			}
+		}
+	})
+}
+
+func Test_lowerCamelCase(t *testing.T) {
+	g := newTestGenerator(t, Options{})
+	for name, want := range map[string]string{
+		"user_id":      "userID",
+		"id":           "id",
+		"HTTPServer":   "httpServer",
+		"TOTAL_AMOUNT": "totalAmount",
+		"userId":       "userID",
+		"":             "",
+	} {
+		if lowerCamel := g.lowerCamelCase(name); lowerCamel != want {
+			t.Error("lowerCamelCase: name=" + name + " want=" + want + " current=" + lowerCamel)
+		}
+	}
+}
+
+func Test_snakeCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"userId":       "user_id",
+		"HTTPServer":   "http_server",
+		"TOTAL_AMOUNT": "total_amount",
+		"first name":   "first_name",
+		"utf8_string":  "utf8_string",
+	} {
+		if snake := snakeCase(name); snake != want {
+			t.Error("snakeCase: name=" + name + " want=" + want + " current=" + snake)
+		}
+	}
+}
+
+func Test_identifierScope_declare(t *testing.T) {
+	t.Run("正常系_CollisionSuffix", func(t *testing.T) {
+		scope := newTestGenerator(t, Options{}).newIdentifierScope("struct Users")
+		for _, tt := range []struct {
+			goName, original, want string
+		}{
+			{"UserID", "user_id", "UserID"},
+			{"UserID", "userId", "UserID_2"},
+			{"UserID_3", "UserID_3", "UserID_3"},
+			{"UserID", "USER_ID", "UserID_4"},
+			{"Name", "name", "Name"},
+		} {
+			declared, err := scope.declare(tt.goName, tt.original)
+			if err != nil {
+				t.Error(err)
+			}
+			if declared != tt.want {
+				t.Error("declare: original=" + tt.original + " want=" + tt.want + " current=" + declared)
+			}
+		}
+	})
+
+	t.Run("異常系_CollisionError", func(t *testing.T) {
+		scope := newTestGenerator(t, Options{Collision: CollisionError}).newIdentifierScope("package")
+		if _, err := scope.declare("EventsRaw", "events-raw"); err != nil {
+			t.Error(err)
+		}
+		if _, err := scope.declare("EventsRaw", "events_raw"); !errors.Is(err, ErrIdentifierCollision) {
+			t.Error(err)
+		}
+	})
+}
+
+func Test_generateStructs_collision(t *testing.T) {
+	const (
+		// 正しい出力
+		testStructCode = "type Events struct {\n" +
+			"\tUserID string `bigquery:\"user_id\"`\n" +
+			"\tUserID_2 string `bigquery:\"userId\"`\n" +
+			"\tPayload *EventsPayload_2 `bigquery:\"payload\"`\n" +
+			"}\n" +
+			"\n" +
+			"// EventsPayload_2 is BigQuery RECORD field `payload` schema struct in Events.\n" +
+			"type EventsPayload_2 struct {\n" +
+			"\tID int64 `bigquery:\"id\"`\n" +
+			"}\n"
+	)
+	var (
+		testSchema = []*FieldSchema{
+			{Name: "user_id", Type: bigquery.StringFieldType},
+			{Name: "userId", Type: bigquery.StringFieldType},
+			{Name: "payload", Type: bigquery.RecordFieldType, Fields: []*FieldSchema{
+				{Name: "id", Type: bigquery.IntegerFieldType},
+			}},
+		}
+	)
+
+	g := newTestGenerator(t, Options{})
+	typeScope := g.newIdentifierScope("package")
+	for _, name := range []string{"events_payload", "events"} {
+		if _, err := typeScope.declare(g.toGoName(name), name); err != nil {
+			t.Error(err)
+		}
+	}
+
+	generatedCode, _, err := generateTestStructCode(g, "Events", "events", testSchema, typeScope)
+	if err != nil {
+		t.Error(err)
+	}
+	if generatedCode != testStructCode {
+		t.Error("generateStructs: want=`" + testStructCode + "` current=`" + generatedCode + "`")
+	}
+}
@@ -0,0 +1,449 @@
+package bqschemagen
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// NamingMode is how the BigQuery table and column names are converted into Go identifiers.
+type NamingMode string
+
+const (
+	// NamingModeCamel converts `user_id` into `UserID`.
+	NamingModeCamel NamingMode = "camel"
+	// NamingModeLegacy only upper-cases the initial, converting `user_id` into `User_id`.
+	NamingModeLegacy NamingMode = "legacy"
+)
+
+// CollisionStrategy is how two names that map to the same Go identifier, or to the same name of a struct tag in a struct, are resolved.
+type CollisionStrategy string
+
+const (
+	// CollisionSuffix appends the smallest free suffix `_2`, `_3`, ... to the later name.
+	CollisionSuffix CollisionStrategy = "suffix"
+	// CollisionError fails the generation with ErrIdentifierCollision.
+	CollisionError CollisionStrategy = "error"
+)
+
+// NullableMode is the Go type of the NULLABLE columns.
+type NullableMode string
+
+const (
+	// NullableModeValue generates the plain values (e.g. int64), which are loaded with the zero values for NULL.
+	// The NULLABLE RECORD columns are the pointers to the nested structs in every mode, because a NULL RECORD can not be loaded into a struct.
+	NullableModeValue NullableMode = "value"
+	// NullableModeNull generates bigquery.NullInt64 etc.
+	NullableModeNull NullableMode = "null"
+	// NullableModePointer generates *int64 etc. for the other libraries (e.g. encoding/json), which bigquery.RowIterator can not load.
+	// The types that are nil for NULL (e.g. []byte and *big.Rat) and the nested structs of RECORD can be loaded.
+	NullableModePointer NullableMode = "pointer"
+)
+
+// JSONType is the Go type of the JSON columns.
+type JSONType string
+
+const (
+	JSONTypeString JSONType = "string"
+	// JSONTypeRawMessage generates json.RawMessage, which bigquery.RowIterator can not load.
+	JSONTypeRawMessage JSONType = "json.RawMessage"
+)
+
+const defaultPackageName = "bqschema"
+
+// ShardMode is how the date-sharded tables such as `events_20240101` are generated.
+type ShardMode string
+
+const (
+	// ShardModeOff generates a struct per shard.
+	ShardModeOff ShardMode = "off"
+	// ShardModeMerge generates a struct per prefix from the latest shard, merging the compatible columns of the older shards with a warning.
+	ShardModeMerge ShardMode = "merge"
+	// ShardModeStrict generates a struct per prefix like ShardModeMerge, but fails with ErrShardSchemaMismatch if the schemas of the shards diverge.
+	ShardModeStrict ShardMode = "strict"
+)
+
+// GoType is a Go type used in the generated code.
+type GoType struct {
+	// Name is the type as written in the generated code (e.g. `*decimal.Decimal`).
+	Name string
+	// ImportPath is the import path of the package of the type, or empty for the predeclared types and the types in the generated package.
+	ImportPath string
+}
+
+// Options configures Generator. The zero value generates the same code as the CLI without any options,
+// except for the package name, which is `bqschema` instead of the name of the directory of the output file.
+type Options struct {
+	// NamingMode is NamingModeCamel by default.
+	NamingMode NamingMode
+	// Initialisms are the words upper-cased by NamingModeCamel. DefaultInitialisms is used if it is nil.
+	Initialisms []string
+	// Collision is CollisionSuffix by default.
+	Collision CollisionStrategy
+
+	// TypeMap overrides the Go type of a BigQuery type.
+	// INTERVAL is *bigquery.IntervalValue by default, which bigquery.RowIterator can not load into a struct field like any other Go type.
+	TypeMap map[bigquery.FieldType]GoType
+	// ColumnTypes overrides the Go type of a column keyed by `table.column`, or `table.record.column` for the nested RECORD fields.
+	// The type is used as is, so REPEATED and NullableMode are not applied.
+	// The key applies to the table of the table ID in every dataset of GenerateDatasets and GeneratePackages, unless it is qualified
+	// by the dataset as `dataset.table.column` or by the project and the dataset as `project:dataset.table.column`.
+	ColumnTypes map[string]GoType
+	// ColumnNames overrides the Go field name of a column keyed like ColumnTypes.
+	ColumnNames map[string]string
+	// NullableMode is NullableModeValue by default.
+	NullableMode NullableMode
+	// JSONType is JSONTypeString by default.
+	JSONType JSONType
+
+	// Include selects the tables to generate by the table ID patterns, and all tables are generated if it is empty.
+	// A pattern is a glob of path.Match (e.g. `events_*`), or an unanchored regular expression prefixed with RegexpPatternPrefix (e.g. `re:^(users|orders)$`).
+	Include []string
+	// Exclude skips the tables that match any of the table ID patterns like Include, even if Include selects them (e.g. `tmp_*`).
+	Exclude []string
+
+	// CollapseShards is ShardModeOff by default.
+	CollapseShards ShardMode
+
+	// Concurrency is the number of the table schemas read at the same time, 8 by default.
+	Concurrency int
+
+	// MaxAttempts is the number of the attempts of a request to a SchemaSource that fails by a transient error
+	// such as 5xx, 429 or rateLimitExceeded of the BigQuery API, including the first one, 5 by default.
+	// The retries wait for the jittered exponential backoff.
+	MaxAttempts int
+	// RequestsPerSecond limits the requests to a SchemaSource of all workers, and is unlimited if it is 0.
+	RequestsPerSecond float64
+
+	// Strict fails the generation with TableErrors if any table or shard can not be read or generated, instead of skipping it with a warning.
+	// Without Strict, only the tables that can not be generated or can not be read by a 4xx error of the Google APIs are skipped.
+	Strict bool
+
+	// PackageName is the package name of the generated code, `bqschema` by default.
+	PackageName string
+	// Tags are the struct tags added to the fields after the `bigquery` tag, such as json, db, avro or parquet.
+	Tags []TagOption
+	// Template is the text/template that redefines the templates of DefaultTemplate such as {{define "struct"}}, e.g. to add methods.
+	Template string
+
+	// Logger receives the INFO and WARN lines such as the skipped tables and the retries, log.Default() by default.
+	// Set log.New(io.Discard, "", 0) to silence them.
+	Logger *log.Logger
+	// Debug prints the generated code to DebugWriter before and after formatting.
+	Debug bool
+	// DebugWriter receives the generated code printed by Debug, os.Stdout by default.
+	DebugWriter io.Writer
+}
+
+// withDefaults returns opts with the zero fields set to the defaults.
+func (opts Options) withDefaults() Options {
+	if opts.NamingMode == "" {
+		opts.NamingMode = NamingModeCamel
+	}
+	if opts.Initialisms == nil {
+		opts.Initialisms = DefaultInitialisms
+	}
+	if opts.Collision == "" {
+		opts.Collision = CollisionSuffix
+	}
+	if opts.NullableMode == "" {
+		opts.NullableMode = NullableModeValue
+	}
+	if opts.JSONType == "" {
+		opts.JSONType = JSONTypeString
+	}
+	if opts.CollapseShards == "" {
+		opts.CollapseShards = ShardModeOff
+	}
+	if opts.Concurrency == 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	if opts.MaxAttempts == 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.PackageName == "" {
+		opts.PackageName = defaultPackageName
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+	if opts.DebugWriter == nil {
+		opts.DebugWriter = os.Stdout
+	}
+	if opts.Tags != nil {
+		tags := make([]TagOption, len(opts.Tags))
+		for i, tag := range opts.Tags {
+			if tag.Naming == "" {
+				tag.Naming = TagNamingOriginal
+			}
+			tags[i] = tag
+		}
+		opts.Tags = tags
+	}
+	return opts
+}
+
+// validate returns an error if opts has an unknown mode or an invalid override.
+func (opts Options) validate() (err error) {
+	switch opts.NamingMode {
+	case NamingModeCamel, NamingModeLegacy:
+	default:
+		return fmt.Errorf("invalid NamingMode: %s", opts.NamingMode)
+	}
+	switch opts.Collision {
+	case CollisionSuffix, CollisionError:
+	default:
+		return fmt.Errorf("invalid Collision: %s", opts.Collision)
+	}
+	switch opts.NullableMode {
+	case NullableModeValue, NullableModeNull, NullableModePointer:
+	default:
+		return fmt.Errorf("invalid NullableMode: %s", opts.NullableMode)
+	}
+	switch opts.JSONType {
+	case JSONTypeString, JSONTypeRawMessage:
+	default:
+		return fmt.Errorf("invalid JSONType: %s", opts.JSONType)
+	}
+	switch opts.CollapseShards {
+	case ShardModeOff, ShardModeMerge, ShardModeStrict:
+	default:
+		return fmt.Errorf("invalid CollapseShards: %s", opts.CollapseShards)
+	}
+	if opts.Concurrency < 0 {
+		return fmt.Errorf("invalid Concurrency: %d", opts.Concurrency)
+	}
+	if opts.MaxAttempts < 0 {
+		return fmt.Errorf("invalid MaxAttempts: %d", opts.MaxAttempts)
+	}
+	if opts.RequestsPerSecond < 0 {
+		return fmt.Errorf("invalid RequestsPerSecond: %g", opts.RequestsPerSecond)
+	}
+	if !token.IsIdentifier(opts.PackageName) || opts.PackageName == "_" {
+		return fmt.Errorf("invalid PackageName: %s", opts.PackageName)
+	}
+	if err = validateTagOptions(opts.Tags); err != nil {
+		return fmt.Errorf("Tags: %w", err)
+	}
+
+	for bigqueryFieldType, goType := range opts.TypeMap {
+		if err = validateTypeMapKey(bigqueryFieldType); err != nil {
+			return fmt.Errorf("TypeMap: %w", err)
+		}
+		if goType.Name == "" {
+			return fmt.Errorf("TypeMap: type is empty: %s", bigqueryFieldType)
+		}
+	}
+	for columnPath, goType := range opts.ColumnTypes {
+		if err = validateColumnPath(columnPath); err != nil {
+			return fmt.Errorf("ColumnTypes: %w", err)
+		}
+		if goType.Name == "" {
+			return fmt.Errorf("ColumnTypes: type is empty: %s", columnPath)
+		}
+	}
+	for columnPath, name := range opts.ColumnNames {
+		if err = validateColumnPath(columnPath); err != nil {
+			return fmt.Errorf("ColumnNames: %w", err)
+		}
+		if !token.IsIdentifier(name) || !token.IsExported(name) {
+			return fmt.Errorf("ColumnNames: field name must be an exported Go identifier: %s=%s", columnPath, name)
+		}
+	}
+
+	return nil
+}
+
+// validateTypeMapKey returns an error if the Go type of bigqueryFieldType can not be overridden.
+func validateTypeMapKey(bigqueryFieldType bigquery.FieldType) (err error) {
+	if bigqueryFieldType == bigquery.RecordFieldType {
+		return fmt.Errorf("RECORD is generated as a nested struct and can not be overridden")
+	}
+	if _, err = (&Generator{}).goType(bigqueryFieldType, false); err != nil {
+		return fmt.Errorf("goType: %w", err)
+	}
+	return nil
+}
+
+// validateColumnPath returns an error if columnPath is not `table.column` or `table.record.column`,
+// which may be qualified by `dataset.` or `project:dataset.`.
+func validateColumnPath(columnPath string) (err error) {
+	path, minNames := columnPath, 2
+	if colon := strings.LastIndex(columnPath, ":"); colon >= 0 {
+		if colon == 0 {
+			return fmt.Errorf("invalid column, expected project:dataset.table.column: %s", columnPath)
+		}
+		path, minNames = columnPath[colon+1:], 3
+	}
+	names := strings.Split(path, ".")
+	if len(names) < minNames {
+		return fmt.Errorf("invalid column, expected table.column: %s", columnPath)
+	}
+	for _, name := range names {
+		if name == "" {
+			return fmt.Errorf("invalid column, expected table.column: %s", columnPath)
+		}
+	}
+	return nil
+}
+
+// ParseGoType parses a package-qualified type such as `*github.com/shopspring/decimal.Decimal` into
+// the Go type `*decimal.Decimal` and the import path `github.com/shopspring/decimal`.
+// A type without a package such as `string` is returned as is.
+func ParseGoType(qualified string) (goType GoType, err error) {
+	if qualified == "" {
+		return GoType{}, fmt.Errorf("type is empty")
+	}
+
+	// NOTE(ginokent): keep pointer and slice prefixes such as `*` or `[]*`
+	rest := strings.TrimLeft(qualified, "*[]")
+	prefix := qualified[:len(qualified)-len(rest)]
+
+	lastSlash := strings.LastIndex(rest, "/")
+	dot := strings.LastIndex(rest[lastSlash+1:], ".")
+	if dot < 0 {
+		if lastSlash >= 0 {
+			return GoType{}, fmt.Errorf("type name is missing, expected import/path.Type: %s", qualified)
+		}
+		return GoType{Name: qualified}, nil
+	}
+	dot += lastSlash + 1
+
+	pkg, typeName := rest[:dot], rest[dot+1:]
+	if pkg == "" || typeName == "" {
+		return GoType{}, fmt.Errorf("invalid type, expected import/path.Type: %s", qualified)
+	}
+
+	return GoType{Name: prefix + importPathToAssumedName(pkg) + "." + typeName, ImportPath: pkg}, nil
+}
+
+// importPathToAssumedName returns the package name that is assumed from importPath.
+// NOTE(ginokent): ref. https://github.com/golang/tools/blob/v0.48.0/internal/imports/fix.go (ImportPathToAssumedName)
+func importPathToAssumedName(importPath string) (name string) {
+	base := path.Base(importPath)
+	if strings.HasPrefix(base, "v") {
+		if _, err := strconv.Atoi(base[1:]); err == nil {
+			dir := path.Dir(importPath)
+			if dir != "." {
+				base = path.Base(dir)
+			}
+		}
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexFunc(base, func(r rune) bool {
+		return !(unicode.IsLetter(r) || r == '_' || unicode.IsDigit(r))
+	}); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+// ParseTypeMap parses comma-separated BIGQUERY_TYPE=import/path.Type entries into Options.TypeMap.
+// The Standard SQL type names such as INT64 are accepted.
+func ParseTypeMap(typeMapCSV string) (typeMap map[bigquery.FieldType]GoType, err error) {
+	typeMap = make(map[bigquery.FieldType]GoType)
+	for _, entry := range strings.Split(typeMapCSV, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid type map entry, expected BIGQUERY_TYPE=import/path.Type: %s", entry)
+		}
+
+		bigqueryFieldType := NormalizeFieldType(strings.TrimSpace(kv[0]))
+		if err = validateTypeMapKey(bigqueryFieldType); err != nil {
+			return nil, fmt.Errorf("validateTypeMapKey: %s, %w", entry, err)
+		}
+		if _, ok := typeMap[bigqueryFieldType]; ok {
+			return nil, fmt.Errorf("duplicate type map entry for %s: %s", bigqueryFieldType, entry)
+		}
+
+		var goType GoType
+		goType, err = ParseGoType(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("ParseGoType: %w", err)
+		}
+		typeMap[bigqueryFieldType] = goType
+	}
+
+	return typeMap, nil
+}
+
+// parseColumnMap parses comma-separated table.column=value entries.
+func parseColumnMap(columnMapCSV string) (columnMap map[string]string, err error) {
+	columnMap = make(map[string]string)
+	for _, entry := range strings.Split(columnMapCSV, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid column map entry, expected table.column=value: %s", entry)
+		}
+
+		columnPath, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if err = validateColumnPath(columnPath); err != nil {
+			return nil, fmt.Errorf("validateColumnPath: %w", err)
+		}
+		if value == "" {
+			return nil, fmt.Errorf("value is empty: %s", entry)
+		}
+		if _, ok := columnMap[columnPath]; ok {
+			return nil, fmt.Errorf("duplicate column map entry for %s: %s", columnPath, entry)
+		}
+
+		columnMap[columnPath] = value
+	}
+
+	return columnMap, nil
+}
+
+// ParseColumnTypeMap parses comma-separated table.column=import/path.Type entries into Options.ColumnTypes.
+func ParseColumnTypeMap(columnTypeCSV string) (columnTypes map[string]GoType, err error) {
+	var columnMap map[string]string
+	columnMap, err = parseColumnMap(columnTypeCSV)
+	if err != nil {
+		return nil, fmt.Errorf("parseColumnMap: %w", err)
+	}
+
+	columnTypes = make(map[string]GoType, len(columnMap))
+	for columnPath, qualified := range columnMap {
+		var goType GoType
+		goType, err = ParseGoType(qualified)
+		if err != nil {
+			return nil, fmt.Errorf("ParseGoType: column=%s, %w", columnPath, err)
+		}
+		columnTypes[columnPath] = goType
+	}
+
+	return columnTypes, nil
+}
+
+// ParseColumnNameMap parses comma-separated table.column=GoName entries into Options.ColumnNames.
+func ParseColumnNameMap(columnNameCSV string) (columnNames map[string]string, err error) {
+	columnNames, err = parseColumnMap(columnNameCSV)
+	if err != nil {
+		return nil, fmt.Errorf("parseColumnMap: %w", err)
+	}
+
+	for columnPath, name := range columnNames {
+		if !token.IsIdentifier(name) || !token.IsExported(name) {
+			return nil, fmt.Errorf("field name must be an exported Go identifier: %s=%s", columnPath, name)
+		}
+	}
+
+	return columnNames, nil
+}
@@ -0,0 +1,189 @@
+package bqschemagen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// columnOverride is a key of Options.ColumnTypes or Options.ColumnNames resolved for the datasets of a generation.
+type columnOverride struct {
+	// key is the key of Options.ColumnTypes or Options.ColumnNames.
+	key string
+	// projectID and datasetID qualify the datasets that the override applies to, and are empty if the key is not qualified.
+	projectID string
+	datasetID string
+	// columnPath is the key without the qualifiers, `table.column` or `table.record.column`.
+	columnPath string
+}
+
+// tableID returns the table ID of the column override.
+func (o columnOverride) tableID() string {
+	return strings.SplitN(o.columnPath, ".", 2)[0]
+}
+
+// appliesTo reports whether the column override applies to the tables of dataset.
+func (o columnOverride) appliesTo(dataset Dataset) bool {
+	return (o.projectID == "" || o.projectID == dataset.ProjectID) && (o.datasetID == "" || o.datasetID == dataset.DatasetID)
+}
+
+// qualifiers returns the number of the qualifiers, which orders the column overrides from the least specific one.
+func (o columnOverride) qualifiers() (n int) {
+	if o.projectID != "" {
+		n++
+	}
+	if o.datasetID != "" {
+		n++
+	}
+	return n
+}
+
+// resolveColumnOverrides returns the keys of Options.ColumnTypes and Options.ColumnNames resolved for datasets, ordered from the least specific one.
+// A key `project:dataset.table.column` applies to the dataset of the project, and a key `dataset.table.column` applies to the datasets of the dataset ID
+// if the dataset ID is the DatasetID of one of datasets. The other keys `table.column` apply to the table of the table ID in every dataset.
+func (g *Generator) resolveColumnOverrides(datasets []Dataset) (overrides []columnOverride) {
+	datasetIDs := make(map[string]bool, len(datasets))
+	for _, dataset := range datasets {
+		if dataset.DatasetID != "" {
+			datasetIDs[dataset.DatasetID] = true
+		}
+	}
+
+	for _, key := range g.columnOverrideKeys() {
+		o := columnOverride{key: key, columnPath: key}
+		// NOTE(ginokent): the domain-scoped project IDs (e.g. `example.com:project`) have a colon, so the project ID ends at the last colon.
+		if colon := strings.LastIndex(key, ":"); colon >= 0 {
+			o.projectID, o.columnPath = key[:colon], key[colon+1:]
+			o.datasetID, o.columnPath, _ = strings.Cut(o.columnPath, ".")
+		} else if names := strings.SplitN(key, ".", 3); len(names) == 3 && datasetIDs[names[0]] {
+			o.datasetID, o.columnPath = names[0], names[1]+"."+names[2]
+		}
+		overrides = append(overrides, o)
+	}
+	sort.SliceStable(overrides, func(i, j int) bool { return overrides[i].qualifiers() < overrides[j].qualifiers() })
+
+	return overrides
+}
+
+// columnOverrideKeys returns the sorted keys of Options.ColumnTypes and Options.ColumnNames.
+func (g *Generator) columnOverrideKeys() (keys []string) {
+	uniq := make(map[string]bool)
+	for key := range g.opts.ColumnTypes {
+		uniq[key] = true
+	}
+	for key := range g.opts.ColumnNames {
+		uniq[key] = true
+	}
+	for key := range uniq {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// withColumnOverrides returns the Generator for the tables of dataset, whose Options.ColumnTypes and Options.ColumnNames are keyed by `table.column`.
+// The key qualified by the project and the dataset wins over the key qualified by the dataset, which wins over the key that is not qualified.
+func (g *Generator) withColumnOverrides(overrides []columnOverride, dataset Dataset) *Generator {
+	if len(overrides) == 0 {
+		return g
+	}
+
+	columnTypes := make(map[string]GoType)
+	columnNames := make(map[string]string)
+	for _, o := range overrides {
+		if !o.appliesTo(dataset) {
+			continue
+		}
+		if goType, ok := g.opts.ColumnTypes[o.key]; ok {
+			columnTypes[o.columnPath] = goType
+		}
+		if name, ok := g.opts.ColumnNames[o.key]; ok {
+			columnNames[o.columnPath] = name
+		}
+	}
+
+	datasetGenerator := *g
+	datasetGenerator.opts.ColumnTypes, datasetGenerator.opts.ColumnNames = columnTypes, columnNames
+	return &datasetGenerator
+}
+
+// validateColumnOverrideTables returns an error if a column override names a table that is in none of the datasets it applies to.
+// datasetTableIDs[i] are the table IDs of datasets[i].
+func validateColumnOverrideTables(overrides []columnOverride, datasets []Dataset, datasetTableIDs [][]string) (err error) {
+	tableIDSets := make([]map[string]bool, len(datasets))
+	for i, tableIDs := range datasetTableIDs {
+		tableIDSets[i] = make(map[string]bool, len(tableIDs))
+		for _, tableID := range tableIDs {
+			tableIDSets[i][tableID] = true
+		}
+	}
+
+	var notFound []string
+	for _, o := range overrides {
+		found := false
+		for i, dataset := range datasets {
+			if o.appliesTo(dataset) && tableIDSets[i][o.tableID()] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			notFound = append(notFound, o.key)
+		}
+	}
+	if len(notFound) > 0 {
+		sort.Strings(notFound)
+		return fmt.Errorf("%w: table not found: %s", ErrColumnOverrideNotFound, strings.Join(notFound, ", "))
+	}
+
+	return nil
+}
+
+// validateColumnOverrideColumns returns an error if a column override names a column that is in none of the tables it applies to.
+// datasetTables[i] are the tables of datasets[i] keyed by the table ID, and the column overrides of the tables that could not be read are not validated.
+func validateColumnOverrideColumns(overrides []columnOverride, datasets []Dataset, datasetTables []map[string]*TableSchema) (err error) {
+	var notFound []string
+	for _, o := range overrides {
+		names := strings.Split(o.columnPath, ".")
+		read, found := false, false
+		for i, dataset := range datasets {
+			table, ok := datasetTables[i][names[0]]
+			if !ok || !o.appliesTo(dataset) {
+				continue
+			}
+			read = true
+			if hasColumn(table.Fields, names[1:]) {
+				found = true
+				break
+			}
+		}
+		if read && !found {
+			notFound = append(notFound, o.key)
+		}
+	}
+	if len(notFound) > 0 {
+		sort.Strings(notFound)
+		return fmt.Errorf("%w: column not found: %s", ErrColumnOverrideNotFound, strings.Join(notFound, ", "))
+	}
+
+	return nil
+}
+
+// hasColumn reports whether fields has the column at names, following RECORD fields.
+func hasColumn(fields []*FieldSchema, names []string) bool {
+	if len(names) == 0 {
+		return false
+	}
+	for _, fieldSchema := range fields {
+		if fieldSchema.Name != names[0] {
+			continue
+		}
+		if len(names) == 1 {
+			return true
+		}
+		return fieldSchema.Type == bigquery.RecordFieldType && hasColumn(fieldSchema.Fields, names[1:])
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package bqschemagen
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func Test_Generator_resolveColumnOverrides(t *testing.T) {
+	g := newTestGenerator(t, Options{ColumnNames: map[string]string{
+		"users.email":                          "Mail",
+		"orders.payload.id":                    "ID",
+		"sales.users.email":                    "SalesMail",
+		"proj-a:sales.users.email":             "ProjASalesMail",
+		"example.com:proj:sales.users.user_id": "UserID",
+	}})
+
+	want := []columnOverride{
+		{key: "orders.payload.id", columnPath: "orders.payload.id"},
+		{key: "users.email", columnPath: "users.email"},
+		{key: "sales.users.email", datasetID: "sales", columnPath: "users.email"},
+		{key: "example.com:proj:sales.users.user_id", projectID: "example.com:proj", datasetID: "sales", columnPath: "users.user_id"},
+		{key: "proj-a:sales.users.email", projectID: "proj-a", datasetID: "sales", columnPath: "users.email"},
+	}
+	overrides := g.resolveColumnOverrides([]Dataset{{ProjectID: "proj-a", DatasetID: "sales"}, {ProjectID: "proj-a", DatasetID: "events"}})
+	if !reflect.DeepEqual(overrides, want) {
+		t.Errorf("resolveColumnOverrides: want=%#v current=%#v", want, overrides)
+	}
+
+	columnNames := g.withColumnOverrides(overrides, Dataset{ProjectID: "proj-a", DatasetID: "sales"}).opts.ColumnNames
+	if want := map[string]string{"orders.payload.id": "ID", "users.email": "ProjASalesMail"}; !reflect.DeepEqual(columnNames, want) {
+		t.Errorf("withColumnOverrides: want=%v current=%v", want, columnNames)
+	}
+	columnNames = g.withColumnOverrides(overrides, Dataset{ProjectID: "proj-b", DatasetID: "sales"}).opts.ColumnNames
+	if want := map[string]string{"orders.payload.id": "ID", "users.email": "SalesMail"}; !reflect.DeepEqual(columnNames, want) {
+		t.Errorf("withColumnOverrides: want=%v current=%v", want, columnNames)
+	}
+	columnNames = g.withColumnOverrides(overrides, Dataset{ProjectID: "proj-a", DatasetID: "events"}).opts.ColumnNames
+	if want := map[string]string{"orders.payload.id": "ID", "users.email": "Mail"}; !reflect.DeepEqual(columnNames, want) {
+		t.Errorf("withColumnOverrides: want=%v current=%v", want, columnNames)
+	}
+}
+
+func Test_validateColumnOverrides(t *testing.T) {
+	datasets := []Dataset{{ProjectID: "proj-a", DatasetID: "sales"}, {ProjectID: "proj-a", DatasetID: "events"}}
+	datasetTables := []map[string]*TableSchema{
+		{"users": {TableID: "users", Fields: []*FieldSchema{{Name: "email", Type: bigquery.StringFieldType}}}},
+		{"users": {TableID: "users", Fields: []*FieldSchema{{Name: "user_id", Type: bigquery.IntegerFieldType}}}},
+	}
+	datasetTableIDs := [][]string{{"users"}, {"users", "clicks"}}
+
+	t.Run("正常系", func(t *testing.T) {
+		g := newTestGenerator(t, Options{ColumnNames: map[string]string{"users.email": "Mail", "events.users.user_id": "UID", "events.clicks.id": "ID"}})
+		overrides := g.resolveColumnOverrides(datasets)
+		if err := validateColumnOverrideTables(overrides, datasets, datasetTableIDs); err != nil {
+			t.Error(err)
+		}
+		// NOTE(ginokent): clicks could not be read, so events.clicks.id is not validated.
+		if err := validateColumnOverrideColumns(overrides, datasets, datasetTables); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("異常系_table_not_found", func(t *testing.T) {
+		for _, key := range []string{"orders.id", "sales.clicks.id", "proj-b:events.clicks.id"} {
+			overrides := newTestGenerator(t, Options{ColumnNames: map[string]string{key: "ID"}}).resolveColumnOverrides(datasets)
+			if err := validateColumnOverrideTables(overrides, datasets, datasetTableIDs); !errors.Is(err, ErrColumnOverrideNotFound) || !strings.Contains(err.Error(), key) {
+				t.Errorf("validateColumnOverrideTables: key=%s, %v", key, err)
+			}
+		}
+	})
+
+	t.Run("異常系_column_not_found", func(t *testing.T) {
+		for _, key := range []string{"users.name", "events.users.email", "proj-a:sales.users.user_id"} {
+			overrides := newTestGenerator(t, Options{ColumnNames: map[string]string{key: "Name"}}).resolveColumnOverrides(datasets)
+			if err := validateColumnOverrideColumns(overrides, datasets, datasetTables); !errors.Is(err, ErrColumnOverrideNotFound) || !strings.Contains(err.Error(), key) {
+				t.Errorf("validateColumnOverrideColumns: key=%s, %v", key, err)
+			}
+		}
+	})
+}
+
+func Test_Generator_GenerateDatasets_columnOverrides(t *testing.T) {
+	datasets := []Dataset{
+		{ProjectID: "proj-a", DatasetID: "sales", Source: NewMemorySource(&TableSchema{TableID: "users", Fields: []*FieldSchema{
+			{Name: "user_id", Type: bigquery.IntegerFieldType},
+			{Name: "email", Type: bigquery.StringFieldType},
+		}})},
+		{ProjectID: "proj-a", DatasetID: "events", Source: NewMemorySource(&TableSchema{TableID: "users", Fields: []*FieldSchema{
+			{Name: "user_id", Type: bigquery.IntegerFieldType},
+		}})},
+	}
+
+	g := newTestGenerator(t, Options{ColumnNames: map[string]string{"users.email": "Mail", "events.users.user_id": "UID"}})
+	generatedCode, err := g.GenerateDatasets(context.Background(), datasets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"type SalesUsers struct {\n\tUserID int64  `bigquery:\"user_id\"`\n\tMail   string `bigquery:\"email\"`\n}\n",
+		"type EventsUsers struct {\n\tUID int64 `bigquery:\"user_id\"`\n}\n",
+	} {
+		if !strings.Contains(string(generatedCode), want) {
+			t.Error("GenerateDatasets: want=`" + want + "` current=`" + string(generatedCode) + "`")
+		}
+	}
+}
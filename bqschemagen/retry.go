@@ -0,0 +1,134 @@
+package bqschemagen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/api/googleapi"
+)
+
+// ErrRetriesExhausted is returned when a request to a SchemaSource still fails by a transient error after Options.MaxAttempts attempts.
+// Generate fails with it instead of skipping the table, so that a table is never dropped from the code for a transient reason.
+var ErrRetriesExhausted = errors.New("retries exhausted")
+
+const (
+	// defaultMaxAttempts is the default of Options.MaxAttempts.
+	defaultMaxAttempts = 5
+)
+
+// NOTE(ginokent): variables for the tests.
+var (
+	// retryInitialBackoff is the upper bound of the first backoff, which doubles for each retry up to retryMaxBackoff.
+	retryInitialBackoff = 500 * time.Millisecond
+	retryMaxBackoff     = 30 * time.Second
+)
+
+// isRetryable reports whether err is a transient error, which is 5xx, 429 or rateLimitExceeded of the Google APIs,
+// or a transport error that the BigQuery client retries such as a reset connection or a timeout.
+// NOTE(ginokent): ref. https://cloud.google.com/bigquery/docs/error-messages and
+// https://github.com/googleapis/google-cloud-go/blob/bigquery/v1.85.0/bigquery/bigquery.go#L271-L324
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		if apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError {
+			return true
+		}
+		for _, item := range apiErr.Errors {
+			switch item.Reason {
+			case "rateLimitExceeded", "backendError", "internalError":
+				return true
+			}
+		}
+		return false
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	// NOTE(ginokent): http2 returns these errors by errors.New. ref. https://github.com/googleapis/google-cloud-go/issues/1793
+	for _, message := range []string{"http2: stream closed", "http2: client connection lost"} {
+		if strings.Contains(err.Error(), message) {
+			return true
+		}
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && (strings.Contains(urlErr.Error(), "connection refused") || strings.Contains(urlErr.Error(), "connection reset")) {
+		return true
+	}
+	var timeoutErr interface{ Timeout() bool }
+	return errors.As(err, &timeoutErr) && timeoutErr.Timeout()
+}
+
+// isSkippable reports whether the table that can not be read by err can be skipped, which is a definite 4xx error of the Google APIs
+// such as 404 of a table deleted after it is listed or 403 of a table that can not be accessed.
+// The other errors may be transient, so a table is never dropped from the code by them.
+func isSkippable(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code >= http.StatusBadRequest && apiErr.Code < http.StatusInternalServerError && !isRetryable(err)
+}
+
+// requester calls a SchemaSource with the rate limit and the retries of Options, which are shared by all workers of a Generate call.
+type requester struct {
+	limiter     *rate.Limiter
+	maxAttempts int
+	logger      *log.Logger
+}
+
+func (g *Generator) newRequester() *requester {
+	limit := rate.Inf
+	if g.opts.RequestsPerSecond > 0 {
+		limit = rate.Limit(g.opts.RequestsPerSecond)
+	}
+	return &requester{limiter: rate.NewLimiter(limit, 1), maxAttempts: g.opts.MaxAttempts, logger: g.opts.Logger}
+}
+
+// do calls request until it succeeds, it fails by an error that is not retryable, or maxAttempts attempts fail.
+// The retries wait for the jittered exponential backoff, and all attempts wait for the rate limit.
+func (r *requester) do(ctx context.Context, request func() error) (err error) {
+	for attempt := 1; ; attempt++ {
+		if err = r.limiter.Wait(ctx); err != nil {
+			return fmt.Errorf("limiter.Wait: %w", err)
+		}
+
+		err = request()
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+		if attempt >= r.maxAttempts {
+			return fmt.Errorf("%w: %d attempts: %w", ErrRetriesExhausted, attempt, err)
+		}
+
+		backoff := backoffOf(attempt)
+		warnln(r.logger, fmt.Sprintf("retry in %s: attempt=%d, %s", backoff, attempt, err))
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("ctx.Done: %w, last error: %v", ctx.Err(), err)
+		}
+	}
+}
+
+// backoffOf returns the full jittered backoff before the retry of attempt, which is random up to the exponential bound.
+// NOTE(ginokent): ref. https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+func backoffOf(attempt int) time.Duration {
+	bound := retryMaxBackoff
+	if shift := attempt - 1; shift < 32 && retryInitialBackoff<<shift < retryMaxBackoff {
+		bound = retryInitialBackoff << shift
+	}
+	return time.Duration(rand.Int63n(int64(bound))) + 1
+}
@@ -0,0 +1,244 @@
+package bqschemagen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"golang.org/x/time/rate"
+	"google.golang.org/api/googleapi"
+)
+
+func Test_isRetryable(t *testing.T) {
+	for name, tt := range map[string]struct {
+		err  error
+		want bool
+	}{
+		"500":              {&googleapi.Error{Code: http.StatusInternalServerError}, true},
+		"503":              {&googleapi.Error{Code: http.StatusServiceUnavailable}, true},
+		"429":              {&googleapi.Error{Code: http.StatusTooManyRequests}, true},
+		"403_rateLimit":    {&googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}}, true},
+		"wrapped_500":      {errors.Join(errors.New("table.Metadata"), &googleapi.Error{Code: http.StatusBadGateway}), true},
+		"403_accessDenied": {&googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "accessDenied"}}}, false},
+		"404":              {&googleapi.Error{Code: http.StatusNotFound}, false},
+		"not_googleapi":    {errors.New("error"), false},
+		"context.Canceled": {context.Canceled, false},
+		"unexpected_EOF":   {fmt.Errorf("table.Metadata: %w", io.ErrUnexpectedEOF), true},
+		"http2":            {errors.New("http2: client connection lost"), true},
+		"connection_reset": {&url.Error{Op: "Get", URL: "https://bigquery.googleapis.com", Err: syscall.ECONNRESET}, true},
+		"url_not_found":    {&url.Error{Op: "Get", URL: "https://bigquery.googleapis.com", Err: errors.New("no such host")}, false},
+		"timeout":          {&url.Error{Op: "Get", URL: "https://bigquery.googleapis.com", Err: os.ErrDeadlineExceeded}, true},
+	} {
+		if retryable := isRetryable(tt.err); retryable != tt.want {
+			t.Errorf("isRetryable: %s: want=%t current=%t", name, tt.want, retryable)
+		}
+	}
+}
+
+func Test_isSkippable(t *testing.T) {
+	for name, tt := range map[string]struct {
+		err  error
+		want bool
+	}{
+		"404":                 {&googleapi.Error{Code: http.StatusNotFound}, true},
+		"403_accessDenied":    {&googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "accessDenied"}}}, true},
+		"403_rateLimit":       {&googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}}, false},
+		"ErrRetriesExhausted": {fmt.Errorf("%w: 5 attempts: %w", ErrRetriesExhausted, &googleapi.Error{Code: http.StatusServiceUnavailable}), false},
+		"not_googleapi":       {errors.New("error"), false},
+	} {
+		if skippable := isSkippable(tt.err); skippable != tt.want {
+			t.Errorf("isSkippable: %s: want=%t current=%t", name, tt.want, skippable)
+		}
+	}
+}
+
+// setTestBackoff shortens the backoff of the retries during t.
+func setTestBackoff(t *testing.T) {
+	t.Helper()
+	initial, max := retryInitialBackoff, retryMaxBackoff
+	retryInitialBackoff, retryMaxBackoff = time.Millisecond, 4*time.Millisecond
+	t.Cleanup(func() { retryInitialBackoff, retryMaxBackoff = initial, max })
+}
+
+func Test_requester_do(t *testing.T) {
+	setTestBackoff(t)
+
+	t.Run("正常系_retry_transient_error", func(t *testing.T) {
+		r := &requester{limiter: rate.NewLimiter(rate.Inf, 1), maxAttempts: 5, logger: log.Default()}
+		attempts := 0
+		err := r.do(context.Background(), func() error {
+			attempts++
+			if attempts < 3 {
+				return &googleapi.Error{Code: http.StatusServiceUnavailable}
+			}
+			return nil
+		})
+		if err != nil || attempts != 3 {
+			t.Errorf("do: attempts=%d, %v", attempts, err)
+		}
+	})
+
+	t.Run("異常系_not_retryable", func(t *testing.T) {
+		r := &requester{limiter: rate.NewLimiter(rate.Inf, 1), maxAttempts: 5, logger: log.Default()}
+		attempts := 0
+		err := r.do(context.Background(), func() error {
+			attempts++
+			return &googleapi.Error{Code: http.StatusNotFound}
+		})
+		if err == nil || errors.Is(err, ErrRetriesExhausted) || attempts != 1 {
+			t.Errorf("do: attempts=%d, %v", attempts, err)
+		}
+	})
+
+	t.Run("異常系_ErrRetriesExhausted", func(t *testing.T) {
+		r := &requester{limiter: rate.NewLimiter(rate.Inf, 1), maxAttempts: 3, logger: log.Default()}
+		attempts := 0
+		err := r.do(context.Background(), func() error {
+			attempts++
+			return &googleapi.Error{Code: http.StatusTooManyRequests}
+		})
+		var apiErr *googleapi.Error
+		if !errors.Is(err, ErrRetriesExhausted) || !errors.As(err, &apiErr) || attempts != 3 {
+			t.Errorf("do: attempts=%d, %v", attempts, err)
+		}
+	})
+
+	t.Run("異常系_context_canceled", func(t *testing.T) {
+		initial, max := retryInitialBackoff, retryMaxBackoff
+		retryInitialBackoff, retryMaxBackoff = time.Hour, time.Hour
+		defer func() { retryInitialBackoff, retryMaxBackoff = initial, max }()
+
+		r := &requester{limiter: rate.NewLimiter(rate.Inf, 1), maxAttempts: 5, logger: log.Default()}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := r.do(ctx, func() error {
+			return &googleapi.Error{Code: http.StatusServiceUnavailable}
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Error(err)
+		}
+	})
+
+	t.Run("正常系_RequestsPerSecond", func(t *testing.T) {
+		r := newTestGenerator(t, Options{RequestsPerSecond: 50}).newRequester()
+		start := time.Now()
+		for i := 0; i < 6; i++ {
+			if err := r.do(context.Background(), func() error { return nil }); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// NOTE(ginokent): the first request is not delayed, and the other 5 requests are 20ms apart.
+		if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+			t.Errorf("do: requests are not limited: elapsed=%s", elapsed)
+		}
+	})
+}
+
+func Test_backoffOf(t *testing.T) {
+	setTestBackoff(t)
+
+	for attempt, bound := range map[int]time.Duration{1: time.Millisecond, 2: 2 * time.Millisecond, 3: 4 * time.Millisecond, 10: 4 * time.Millisecond, 100: 4 * time.Millisecond} {
+		for i := 0; i < 100; i++ {
+			if backoff := backoffOf(attempt); backoff <= 0 || backoff > bound {
+				t.Errorf("backoffOf: attempt=%d bound=%s current=%s", attempt, bound, backoff)
+			}
+		}
+	}
+}
+
+// flakySource is the SchemaSource that fails by err, 503 by default, for the first failures reads of each table, or of failingTableID if it is set.
+type flakySource struct {
+	SchemaSource
+	failures       int
+	err            error
+	failingTableID string
+
+	mu       sync.Mutex
+	attempts map[string]int
+}
+
+func (s *flakySource) Table(ctx context.Context, tableID string) (table *TableSchema, err error) {
+	s.mu.Lock()
+	s.attempts[tableID]++
+	attempt := s.attempts[tableID]
+	s.mu.Unlock()
+	if attempt <= s.failures && (s.failingTableID == "" || s.failingTableID == tableID) {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, &googleapi.Error{Code: http.StatusServiceUnavailable, Message: "backend error"}
+	}
+	return s.SchemaSource.Table(ctx, tableID)
+}
+
+func Test_Generator_Generate_retry(t *testing.T) {
+	setTestBackoff(t)
+
+	newSource := func(failures int) *flakySource {
+		return &flakySource{SchemaSource: NewMemorySource(
+			&TableSchema{TableID: "users", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}},
+			&TableSchema{TableID: "events_20240101", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}},
+			&TableSchema{TableID: "events_20240102", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}},
+		), failures: failures, attempts: make(map[string]int)}
+	}
+
+	t.Run("正常系_transient_errors", func(t *testing.T) {
+		generatedCode, err := newTestGenerator(t, Options{CollapseShards: ShardModeMerge}).Generate(context.Background(), newSource(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(generatedCode), "type Users struct") || !strings.Contains(string(generatedCode), "type Events struct") {
+			t.Error("Generate: table is dropped: " + string(generatedCode))
+		}
+	})
+
+	for _, mode := range []ShardMode{ShardModeOff, ShardModeMerge} {
+		mode := mode
+		t.Run("異常系_ErrRetriesExhausted_"+string(mode), func(t *testing.T) {
+			if _, err := newTestGenerator(t, Options{MaxAttempts: 2, CollapseShards: mode}).Generate(context.Background(), newSource(2)); !errors.Is(err, ErrRetriesExhausted) {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("正常系_transient_transport_errors", func(t *testing.T) {
+		source := newSource(2)
+		source.err = &url.Error{Op: "Get", URL: "https://bigquery.googleapis.com", Err: io.ErrUnexpectedEOF}
+		if _, err := newTestGenerator(t, Options{}).Generate(context.Background(), source); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("正常系_skip_4xx", func(t *testing.T) {
+		source := newSource(1)
+		source.err = &googleapi.Error{Code: http.StatusNotFound, Message: "not found"}
+		source.failingTableID = "events_20240101"
+		generatedCode, err := newTestGenerator(t, Options{}).Generate(context.Background(), source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(generatedCode), "type Users struct") || strings.Contains(string(generatedCode), "type Events20240101 struct") {
+			t.Error("Generate: " + string(generatedCode))
+		}
+	})
+
+	t.Run("異常系_not_4xx", func(t *testing.T) {
+		source := newSource(1)
+		source.err = errors.New("unknown error")
+		source.failingTableID = "events_20240101"
+		if _, err := newTestGenerator(t, Options{}).Generate(context.Background(), source); err == nil || !strings.Contains(err.Error(), "unknown error") {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package bqschemagen
+
+import (
+	"cloud.google.com/go/bigquery"
+)
+
+// FieldMode is the mode of a column.
+// NOTE(ginokent): ref. https://cloud.google.com/bigquery/docs/reference/rest/v2/tables#TableFieldSchema.FIELDS.mode
+type FieldMode string
+
+const (
+	FieldModeNullable FieldMode = "NULLABLE"
+	FieldModeRequired FieldMode = "REQUIRED"
+	FieldModeRepeated FieldMode = "REPEATED"
+)
+
+// TableSchema is the schema of a table that the code is generated from, whichever SchemaSource it is read from.
+type TableSchema struct {
+	// TableID is the table ID that the struct name is generated from.
+	TableID string
+	// FullID is the table ID qualified with the project and dataset (e.g. `project:dataset.table`) for the comments.
+	FullID      string
+	Description string
+	Fields      []*FieldSchema
+
+	// TimePartitioning is nil unless the table is partitioned by time.
+	TimePartitioning *TimePartitioning
+	// RangePartitioning is nil unless the table is partitioned by integer range.
+	RangePartitioning *RangePartitioning
+	// Clustering is the clustering columns in order.
+	Clustering []string
+}
+
+// FieldSchema is the schema of a column.
+type FieldSchema struct {
+	Name        string
+	Type        bigquery.FieldType
+	Mode        FieldMode
+	Description string
+	// Fields is the nested columns of a RECORD column.
+	Fields []*FieldSchema
+
+	// Precision and Scale are the parameterized NUMERIC/BIGNUMERIC type parameters, or zero.
+	Precision int64
+	Scale     int64
+	// MaxLength is the parameterized STRING/BYTES type parameter, or zero.
+	MaxLength int64
+	// RangeElementType is the element type of a RANGE column.
+	RangeElementType bigquery.FieldType
+	// PolicyTags is the policy tag resource names of the column.
+	PolicyTags []string
+}
+
+// TimePartitioning is the time-unit column or ingestion-time partitioning of a table.
+type TimePartitioning struct {
+	Type bigquery.TimePartitioningType
+	// Field is the partitioning column, or empty for the ingestion-time partitioning.
+	Field string
+}
+
+// RangePartitioning is the integer range partitioning of a table.
+type RangePartitioning struct {
+	Field    string
+	Start    int64
+	End      int64
+	Interval int64
+}
+
+// nullable reports whether the Go field of the column may be loaded with NULL.
+// NOTE(ginokent): REPEATED fields are never NULL. ref. https://cloud.google.com/bigquery/docs/reference/standard-sql/data-types#array_type
+func (f *FieldSchema) nullable() bool {
+	return f.Mode != FieldModeRequired && f.Mode != FieldModeRepeated
+}
+
+// newTableSchema converts the table metadata of the BigQuery API into TableSchema.
+func newTableSchema(tableID string, md *bigquery.TableMetadata) (table *TableSchema) {
+	table = &TableSchema{
+		TableID:     tableID,
+		FullID:      md.FullID,
+		Description: md.Description,
+		Fields:      newFieldSchemas(md.Schema),
+	}
+
+	if tp := md.TimePartitioning; tp != nil {
+		table.TimePartitioning = &TimePartitioning{Type: tp.Type, Field: tp.Field}
+		if table.TimePartitioning.Type == "" {
+			table.TimePartitioning.Type = bigquery.DayPartitioningType
+		}
+	}
+	if rp := md.RangePartitioning; rp != nil {
+		table.RangePartitioning = &RangePartitioning{Field: rp.Field}
+		if rp.Range != nil {
+			table.RangePartitioning.Start = rp.Range.Start
+			table.RangePartitioning.End = rp.Range.End
+			table.RangePartitioning.Interval = rp.Range.Interval
+		}
+	}
+	if md.Clustering != nil {
+		table.Clustering = md.Clustering.Fields
+	}
+
+	return table
+}
+
+// newFieldSchemas converts the schema of the BigQuery API into FieldSchemas.
+func newFieldSchemas(schema bigquery.Schema) (fields []*FieldSchema) {
+	for _, fieldSchema := range schema {
+		field := &FieldSchema{
+			Name:        fieldSchema.Name,
+			Type:        fieldSchema.Type,
+			Mode:        FieldModeNullable,
+			Description: fieldSchema.Description,
+			Fields:      newFieldSchemas(fieldSchema.Schema),
+			Precision:   fieldSchema.Precision,
+			Scale:       fieldSchema.Scale,
+			MaxLength:   fieldSchema.MaxLength,
+		}
+		switch {
+		case fieldSchema.Repeated:
+			field.Mode = FieldModeRepeated
+		case fieldSchema.Required:
+			field.Mode = FieldModeRequired
+		}
+		if fieldSchema.RangeElementType != nil {
+			field.RangeElementType = fieldSchema.RangeElementType.Type
+		}
+		if fieldSchema.PolicyTags != nil {
+			field.PolicyTags = fieldSchema.PolicyTags.Names
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
@@ -0,0 +1,45 @@
+package bqschemagen
+
+import (
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func Test_newTableSchema(t *testing.T) {
+	md := &bigquery.TableMetadata{
+		FullID:      "project:dataset.events",
+		Description: "events",
+		Schema: bigquery.Schema{
+			{Name: "id", Type: bigquery.IntegerFieldType, Required: true, Description: "event id", PolicyTags: &bigquery.PolicyTagList{Names: []string{"projects/p/locations/l/taxonomies/t/policyTags/1"}}},
+			{Name: "period", Type: bigquery.RangeFieldType, RangeElementType: &bigquery.RangeElementType{Type: bigquery.DateFieldType}},
+			{Name: "items", Type: bigquery.RecordFieldType, Repeated: true, Schema: bigquery.Schema{
+				{Name: "price", Type: bigquery.NumericFieldType, Precision: 10, Scale: 2},
+			}},
+		},
+		TimePartitioning:  &bigquery.TimePartitioning{Field: "created_at"},
+		RangePartitioning: &bigquery.RangePartitioning{Field: "id", Range: &bigquery.RangePartitioningRange{Start: 0, End: 100, Interval: 10}},
+		Clustering:        &bigquery.Clustering{Fields: []string{"id"}},
+	}
+
+	want := &TableSchema{
+		TableID:     "events",
+		FullID:      "project:dataset.events",
+		Description: "events",
+		Fields: []*FieldSchema{
+			{Name: "id", Type: bigquery.IntegerFieldType, Mode: FieldModeRequired, Description: "event id", PolicyTags: []string{"projects/p/locations/l/taxonomies/t/policyTags/1"}},
+			{Name: "period", Type: bigquery.RangeFieldType, Mode: FieldModeNullable, RangeElementType: bigquery.DateFieldType},
+			{Name: "items", Type: bigquery.RecordFieldType, Mode: FieldModeRepeated, Fields: []*FieldSchema{
+				{Name: "price", Type: bigquery.NumericFieldType, Mode: FieldModeNullable, Precision: 10, Scale: 2},
+			}},
+		},
+		TimePartitioning:  &TimePartitioning{Type: bigquery.DayPartitioningType, Field: "created_at"},
+		RangePartitioning: &RangePartitioning{Field: "id", Start: 0, End: 100, Interval: 10},
+		Clustering:        []string{"id"},
+	}
+
+	if table := newTableSchema("events", md); !reflect.DeepEqual(table, want) {
+		t.Errorf("newTableSchema: want=%#v current=%#v", want, table)
+	}
+}
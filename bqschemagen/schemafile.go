@@ -0,0 +1,129 @@
+package bqschemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// schemaFile is a local table schema JSON file.
+type schemaFile struct {
+	tableID string
+	path    string
+}
+
+// tableResourceJSON is the part of the table resource that `bq show --format=prettyjson project:dataset.table` emits.
+// NOTE(ginokent): ref. https://cloud.google.com/bigquery/docs/reference/rest/v2/tables#Table
+type tableResourceJSON struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Schema      struct {
+		Fields json.RawMessage `json:"fields"`
+	} `json:"schema"`
+}
+
+// listSchemaFiles returns the `*.json` files in dir sorted by table ID. The table IDs are the file names without `.json`.
+func listSchemaFiles(dir string) (schemaFiles []schemaFile, err error) {
+	var paths []string
+	paths, err = filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("filepath.Glob: %w", err)
+	}
+
+	for _, path := range paths {
+		schemaFiles = append(schemaFiles, schemaFile{
+			tableID: strings.TrimSuffix(filepath.Base(path), ".json"),
+			path:    path,
+		})
+	}
+	sort.Slice(schemaFiles, func(i, j int) bool { return schemaFiles[i].tableID < schemaFiles[j].tableID })
+
+	return schemaFiles, nil
+}
+
+// readSchemaManifest reads a manifest JSON object that maps table IDs to schema file paths sorted by table ID.
+// The relative paths are resolved from the directory of the manifest.
+func readSchemaManifest(path string) (schemaFiles []schemaFile, err error) {
+	var content []byte
+	content, err = os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	var manifest map[string]string
+	if err = json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: path=%s, %w", path, err)
+	}
+
+	for tableID, schemaPath := range manifest {
+		if tableID == "" || schemaPath == "" {
+			return nil, fmt.Errorf("manifest entry is empty: path=%s, tableID=%s, schemaPath=%s", path, tableID, schemaPath)
+		}
+		if !filepath.IsAbs(schemaPath) {
+			schemaPath = filepath.Join(filepath.Dir(path), schemaPath)
+		}
+		schemaFiles = append(schemaFiles, schemaFile{tableID: tableID, path: schemaPath})
+	}
+	sort.Slice(schemaFiles, func(i, j int) bool { return schemaFiles[i].tableID < schemaFiles[j].tableID })
+
+	return schemaFiles, nil
+}
+
+// readSchemaFile reads the table schema JSON file at path.
+// The file is either the array of fields that `bq show --schema --format=prettyjson` emits and bigquery.SchemaFromJSON accepts,
+// or the table resource that `bq show --format=prettyjson` emits.
+func readSchemaFile(path string) (md *bigquery.TableMetadata, err error) {
+	var content []byte
+	content, err = os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	md = &bigquery.TableMetadata{}
+	fieldsJSON := content
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		var resource tableResourceJSON
+		if err = json.Unmarshal(content, &resource); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: path=%s, %w", path, err)
+		}
+		md.FullID = resource.ID
+		md.Description = resource.Description
+		fieldsJSON = resource.Schema.Fields
+	}
+
+	md.Schema, err = bigquery.SchemaFromJSON(fieldsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("bigquery.SchemaFromJSON: path=%s, %w", path, err)
+	}
+	normalizeFieldTypes(md.Schema)
+
+	return md, nil
+}
+
+// normalizeFieldTypes replaces the Standard SQL type names in the nested fields, which bigquery.SchemaFromJSON does not normalize.
+func normalizeFieldTypes(schema bigquery.Schema) {
+	for _, fieldSchema := range schema {
+		if fieldType, ok := bigqueryFieldTypeAliases[strings.ToUpper(string(fieldSchema.Type))]; ok {
+			fieldSchema.Type = fieldType
+		}
+		normalizeFieldTypes(fieldSchema.Schema)
+	}
+}
+
+// tableFullID returns `project:dataset.table` like bigquery.TableMetadata.FullID, omitting the empty parts.
+func tableFullID(project, dataset, tableID string) (fullID string) {
+	fullID = tableID
+	if dataset != "" {
+		fullID = dataset + "." + fullID
+	}
+	if project != "" {
+		fullID = project + ":" + fullID
+	}
+	return fullID
+}
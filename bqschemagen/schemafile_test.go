@@ -0,0 +1,116 @@
+package bqschemagen
+
+import (
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+const (
+	// listSchemaFiles, readSchemaManifest, readSchemaFile
+	testSchemaDir          = "../test/schema"
+	testSchemaManifest     = "../test/schema.manifest.json"
+	testSchemaFileStories  = "../test/schema/stories.json"
+	testSchemaFileEvents   = "../test/schema/events.json"
+	testSchemaFileNotFound = "../test/schema/notfound.json"
+)
+
+func Test_listSchemaFiles(t *testing.T) {
+	t.Run("正常系_testSchemaDir", func(t *testing.T) {
+		schemaFiles, err := listSchemaFiles(testSchemaDir)
+		if err != nil {
+			t.Error(err)
+		}
+		want := []schemaFile{
+			{tableID: "events", path: testSchemaFileEvents},
+			{tableID: "stories", path: testSchemaFileStories},
+		}
+		if !reflect.DeepEqual(schemaFiles, want) {
+			t.Errorf("listSchemaFiles: want=%v current=%v", want, schemaFiles)
+		}
+	})
+}
+
+func Test_readSchemaManifest(t *testing.T) {
+	t.Run("正常系_testSchemaManifest", func(t *testing.T) {
+		schemaFiles, err := readSchemaManifest(testSchemaManifest)
+		if err != nil {
+			t.Error(err)
+		}
+		want := []schemaFile{
+			{tableID: "events", path: testSchemaFileEvents},
+			{tableID: "hacker_news_stories", path: testSchemaFileStories},
+		}
+		if !reflect.DeepEqual(schemaFiles, want) {
+			t.Errorf("readSchemaManifest: want=%v current=%v", want, schemaFiles)
+		}
+	})
+
+	t.Run("異常系_testErrNoSuchFileOrDirectoryPath", func(t *testing.T) {
+		if _, err := readSchemaManifest(testErrNoSuchFileOrDirectoryPath); err == nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("異常系_not_manifest", func(t *testing.T) {
+		if _, err := readSchemaManifest(testSchemaFileStories); err == nil {
+			t.Error(err)
+		}
+	})
+}
+
+func Test_readSchemaFile(t *testing.T) {
+	t.Run("正常系_fields", func(t *testing.T) {
+		md, err := readSchemaFile(testSchemaFileStories)
+		if err != nil {
+			t.Error(err)
+		}
+		if md.FullID != testEmptyString || len(md.Schema) != 4 {
+			t.Errorf("readSchemaFile: md=%#v", md)
+		}
+		if tags := md.Schema[3]; tags.Name != "tags" || !tags.Repeated || tags.Type != bigquery.StringFieldType {
+			t.Errorf("readSchemaFile: tags=%#v", tags)
+		}
+	})
+
+	t.Run("正常系_table_resource", func(t *testing.T) {
+		md, err := readSchemaFile(testSchemaFileEvents)
+		if err != nil {
+			t.Error(err)
+		}
+		if md.FullID != "example-project:analytics.events" || md.Description != "Application events" {
+			t.Errorf("readSchemaFile: md=%#v", md)
+		}
+		payload := md.Schema[1]
+		if payload.Type != bigquery.RecordFieldType || payload.Schema[0].Type != bigquery.IntegerFieldType {
+			t.Errorf("readSchemaFile: payload=%#v", payload)
+		}
+	})
+
+	t.Run("異常系_testSchemaFileNotFound", func(t *testing.T) {
+		if _, err := readSchemaFile(testSchemaFileNotFound); err == nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("異常系_not_schema", func(t *testing.T) {
+		if _, err := readSchemaFile(testSchemaManifest); err == nil {
+			t.Error(err)
+		}
+	})
+}
+
+func Test_tableFullID(t *testing.T) {
+	for _, tt := range []struct {
+		project, dataset, tableID, want string
+	}{
+		{"p", "d", "t", "p:d.t"},
+		{"", "d", "t", "d.t"},
+		{"", "", "t", "t"},
+	} {
+		if fullID := tableFullID(tt.project, tt.dataset, tt.tableID); fullID != tt.want {
+			t.Error("tableFullID: want=" + tt.want + " current=" + fullID)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package bqschemagen
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// ErrShardSchemaMismatch is returned by ShardModeStrict when the schemas of the date-sharded tables of a family diverge.
+var ErrShardSchemaMismatch = errors.New("shard schemas diverge")
+
+// shardDateLayout is the date suffix of the date-sharded tables such as `events_20240101`.
+// NOTE(ginokent): ref. https://cloud.google.com/bigquery/docs/partitioned-tables#dt_partition_shard
+const shardDateLayout = "20060102"
+
+// splitShardID returns the prefix and the date suffix of a date-sharded table ID such as `events_20240101`,
+// or ok=false if tableID does not end with a valid YYYYMMDD date.
+func splitShardID(tableID string) (prefix, date string, ok bool) {
+	if len(tableID) <= len(shardDateLayout) {
+		return "", "", false
+	}
+	prefix, date = tableID[:len(tableID)-len(shardDateLayout)], tableID[len(tableID)-len(shardDateLayout):]
+	if _, err := time.Parse(shardDateLayout, date); err != nil {
+		return "", "", false
+	}
+	// NOTE(ginokent): the date of `events_120240101` is ambiguous, so a prefix must not end with a digit.
+	if last := prefix[len(prefix)-1]; last >= '0' && last <= '9' {
+		return "", "", false
+	}
+	if strings.TrimRight(prefix, "_") == "" {
+		return "", "", false
+	}
+	return prefix, date, true
+}
+
+// tableEntry is a table to generate, which is the family of the date-sharded tables shardIDs if it has them.
+type tableEntry struct {
+	// tableID is the table ID, or the shard prefix without the trailing `_` (e.g. `events` for `events_20240101`).
+	tableID string
+	// prefix is the shard prefix (e.g. `events_`).
+	prefix string
+	// shardIDs are the table IDs of the shards sorted from the oldest to the latest.
+	shardIDs []string
+}
+
+// tableEntries returns the tables to generate from tableIDs sorted by the table ID.
+// The date-sharded tables are collapsed into a family per prefix unless Options.CollapseShards is ShardModeOff.
+// A family is not collapsed, with a warning, if its table ID is already the table ID of a table or another family (e.g. `events` and `events_20240101`).
+func (g *Generator) tableEntries(tableIDs []string) (entries []tableEntry) {
+	families := make(map[string]*tableEntry)
+	for _, tableID := range tableIDs {
+		prefix, _, ok := splitShardID(tableID)
+		if !ok || g.opts.CollapseShards == ShardModeOff {
+			entries = append(entries, tableEntry{tableID: tableID})
+			continue
+		}
+		family, ok := families[prefix]
+		if !ok {
+			family = &tableEntry{tableID: strings.TrimRight(prefix, "_"), prefix: prefix}
+			families[prefix] = family
+		}
+		family.shardIDs = append(family.shardIDs, tableID)
+	}
+
+	// sources describes the tables that the table IDs of entries are generated from for the warning.
+	sources := make(map[string]string, len(entries)+len(families))
+	for _, entry := range entries {
+		sources[entry.tableID] = "table `" + entry.tableID + "`"
+	}
+	prefixes := make([]string, 0, len(families))
+	for prefix := range families {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		family := families[prefix]
+		// NOTE(ginokent): the shards of a prefix have the same length, so the table IDs are sorted by the date.
+		sort.Strings(family.shardIDs)
+		if source, ok := sources[family.tableID]; ok {
+			warnln(g.opts.Logger, fmt.Sprintf("%s and the date-sharded tables `%s*` both map to `%s`. not collapsing the shards of `%s*`", source, prefix, family.tableID, prefix))
+			for _, shardID := range family.shardIDs {
+				entries = append(entries, tableEntry{tableID: shardID})
+			}
+			continue
+		}
+		sources[family.tableID] = "the date-sharded tables `" + prefix + "*`"
+		entries = append(entries, *family)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].tableID < entries[j].tableID })
+
+	return entries
+}
+
+// CollapseShardIDs returns the table IDs that the structs of tableIDs are generated for, which are sorted.
+// The date-sharded tables are collapsed into the shard prefix without the trailing `_` (e.g. `events` for `events_20240101`)
+// unless Options.CollapseShards is ShardModeOff, and the column overrides of them are keyed by it.
+func (g *Generator) CollapseShardIDs(tableIDs []string) (collapsed []string) {
+	for _, entry := range g.tableEntries(tableIDs) {
+		collapsed = append(collapsed, entry.tableID)
+	}
+	return collapsed
+}
+
+// fetchTableIDs returns the table IDs of entry to read, which are the shards of a family of the date-sharded tables.
+func (entry tableEntry) fetchTableIDs() (tableIDs []string) {
+	if len(entry.shardIDs) == 0 {
+		return []string{entry.tableID}
+	}
+	return entry.shardIDs
+}
+
+// readTable returns the schema of entry from the fetched schemas keyed by the table ID.
+func (g *Generator) readTable(entry tableEntry, fetched map[string]fetchResult) (table *TableSchema, err error) {
+	if len(entry.shardIDs) == 0 {
+		result := fetched[entry.tableID]
+		if result.err != nil {
+			return nil, fmt.Errorf("source.Table: %w", result.err)
+		}
+		return result.table, nil
+	}
+	return g.readShardedTable(entry, fetched)
+}
+
+// readShardedTable merges the fetched schemas of the shards of entry into the schema of the latest shard.
+// The columns that are not in all shards are added as NULLABLE, and the REQUIRED columns that are NULLABLE in a shard become NULLABLE.
+// The incompatible columns such as the columns of different types are generated as the latest shard with a warning.
+// ShardModeStrict fails with ErrShardSchemaMismatch instead if the schemas diverge at all.
+func (g *Generator) readShardedTable(entry tableEntry, fetched map[string]fetchResult) (table *TableSchema, err error) {
+	var latestID string
+	for i := len(entry.shardIDs) - 1; i >= 0; i-- {
+		shardID := entry.shardIDs[i]
+
+		result := fetched[shardID]
+		if result.err != nil {
+			if g.opts.CollapseShards == ShardModeStrict || g.opts.Strict || !isSkippable(result.err) {
+				return nil, fmt.Errorf("source.Table: tableID=%s, %w", shardID, result.err)
+			}
+			warnln(g.opts.Logger, "source.Table: tableID="+shardID+", skip the shard, "+result.err.Error())
+			continue
+		}
+		shard := result.table
+
+		if table == nil {
+			latestID = shardID
+			table = &TableSchema{}
+			*table = *shard
+			table.TableID = entry.tableID
+			if table.FullID != "" {
+				// NOTE(ginokent): the wildcard table that queries all shards. e.g. `project:dataset.events_*`
+				table.FullID = strings.TrimSuffix(table.FullID, shardID) + entry.prefix + "*"
+			}
+			continue
+		}
+
+		var diffs []string
+		table.Fields, diffs = mergeShardFields(table.Fields, shard.Fields, "")
+		if len(diffs) == 0 {
+			continue
+		}
+		if g.opts.CollapseShards == ShardModeStrict {
+			return nil, fmt.Errorf("%w: %s and %s: %s", ErrShardSchemaMismatch, latestID, shardID, strings.Join(diffs, "; "))
+		}
+		warnln(g.opts.Logger, fmt.Sprintf("%s: %s and %s: %s", ErrShardSchemaMismatch, latestID, shardID, strings.Join(diffs, "; ")))
+	}
+
+	if table == nil {
+		return nil, fmt.Errorf("no shard can be read: %s", strings.Join(entry.shardIDs, ", "))
+	}
+	return table, nil
+}
+
+// mergeShardFields merges the fields of an older shard into the fields of the newer shards, and returns the differences.
+// columnPath is the dot-separated path of the parent RECORD field, or empty for the top level.
+// The fields are copied, so the schemas of the shards are not modified.
+func mergeShardFields(fields, older []*FieldSchema, columnPath string) (merged []*FieldSchema, diffs []string) {
+	olderByName := make(map[string]*FieldSchema, len(older))
+	for _, fieldSchema := range older {
+		olderByName[fieldSchema.Name] = fieldSchema
+	}
+	names := make(map[string]bool, len(fields))
+
+	for _, fieldSchema := range fields {
+		names[fieldSchema.Name] = true
+		path := columnPath + fieldSchema.Name
+
+		olderField, ok := olderByName[fieldSchema.Name]
+		if !ok {
+			diffs = append(diffs, "`"+path+"` is not in the older shard")
+			merged = append(merged, nullableField(fieldSchema))
+			continue
+		}
+
+		mergedField, fieldDiffs := mergeShardField(fieldSchema, olderField, path)
+		merged = append(merged, mergedField)
+		diffs = append(diffs, fieldDiffs...)
+	}
+
+	for _, olderField := range older {
+		if names[olderField.Name] {
+			continue
+		}
+		diffs = append(diffs, "`"+columnPath+olderField.Name+"` is only in the older shard")
+		merged = append(merged, nullableField(olderField))
+	}
+
+	return merged, diffs
+}
+
+// mergeShardField merges olderField into fieldSchema of the same name at path.
+func mergeShardField(fieldSchema, olderField *FieldSchema, path string) (merged *FieldSchema, diffs []string) {
+	merged = &FieldSchema{}
+	*merged = *fieldSchema
+
+	if fieldSchema.Type != olderField.Type {
+		return merged, []string{"`" + path + "` is " + string(fieldSchema.Type) + " and " + string(olderField.Type) + " in the older shard (incompatible, the latest is used)"}
+	}
+	mode, olderMode := fieldMode(fieldSchema), fieldMode(olderField)
+	if (mode == FieldModeRepeated) != (olderMode == FieldModeRepeated) {
+		return merged, []string{"`" + path + "` is " + string(mode) + " and " + string(olderMode) + " in the older shard (incompatible, the latest is used)"}
+	}
+	if mode != olderMode {
+		diffs = append(diffs, "`"+path+"` is "+string(mode)+" and "+string(olderMode)+" in the older shard")
+		merged.Mode = FieldModeNullable
+	}
+
+	if fieldSchema.Type == bigquery.RecordFieldType {
+		var recordDiffs []string
+		merged.Fields, recordDiffs = mergeShardFields(fieldSchema.Fields, olderField.Fields, path+".")
+		diffs = append(diffs, recordDiffs...)
+	}
+
+	return merged, diffs
+}
+
+// fieldMode returns the mode of fieldSchema, which is NULLABLE if it is empty.
+func fieldMode(fieldSchema *FieldSchema) FieldMode {
+	if fieldSchema.Mode == "" {
+		return FieldModeNullable
+	}
+	return fieldSchema.Mode
+}
+
+// nullableField returns a copy of fieldSchema that is NULLABLE unless it is REPEATED, for a column that is not in all shards.
+func nullableField(fieldSchema *FieldSchema) *FieldSchema {
+	nullable := &FieldSchema{}
+	*nullable = *fieldSchema
+	if nullable.Mode == FieldModeRequired {
+		nullable.Mode = FieldModeNullable
+	}
+	return nullable
+}
@@ -0,0 +1,180 @@
+package bqschemagen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func Test_splitShardID(t *testing.T) {
+	for tableID, want := range map[string]struct {
+		prefix string
+		ok     bool
+	}{
+		"events_20240101":     {"events_", true},
+		"ga_sessions20170801": {"ga_sessions", true},
+		"events_20241331":     {"", false},
+		"events_2024010":      {"", false},
+		"full_201510":         {"", false},
+		"events_120240101":    {"", false},
+		"20240101":            {"", false},
+		"_20240101":           {"", false},
+		"events":              {"", false},
+	} {
+		prefix, _, ok := splitShardID(tableID)
+		if prefix != want.prefix || ok != want.ok {
+			t.Errorf("splitShardID: tableID=%s want=%v current=%s,%t", tableID, want, prefix, ok)
+		}
+	}
+}
+
+func Test_Generator_CollapseShardIDs(t *testing.T) {
+	tableIDs := []string{"events", "events_20240101", "events_20240102", "full", "full_201510", "logs20240101", "users"}
+
+	t.Run("正常系_ShardModeOff", func(t *testing.T) {
+		if collapsed := newTestGenerator(t, Options{}).CollapseShardIDs(tableIDs); !reflect.DeepEqual(collapsed, tableIDs) {
+			t.Errorf("CollapseShardIDs: want=%v current=%v", tableIDs, collapsed)
+		}
+	})
+
+	t.Run("正常系_ShardModeMerge", func(t *testing.T) {
+		want := []string{"events", "events_20240101", "events_20240102", "full", "full_201510", "logs", "users"}
+		var logs bytes.Buffer
+		if collapsed := newTestGenerator(t, Options{CollapseShards: ShardModeMerge, Logger: log.New(&logs, "", 0)}).CollapseShardIDs(tableIDs); !reflect.DeepEqual(collapsed, want) {
+			t.Errorf("CollapseShardIDs: want=%v current=%v", want, collapsed)
+		}
+		if want := "WARN: table `events` and the date-sharded tables `events_*` both map to `events`. not collapsing the shards of `events_*`\n"; logs.String() != want {
+			t.Errorf("CollapseShardIDs: want=%q current=%q", want, logs.String())
+		}
+	})
+
+	t.Run("正常系_ShardModeMerge_families", func(t *testing.T) {
+		want := []string{"events", "events__20240101", "events__20240102"}
+		if collapsed := newTestGenerator(t, Options{CollapseShards: ShardModeMerge}).CollapseShardIDs([]string{"events__20240102", "events_20240101", "events__20240101"}); !reflect.DeepEqual(collapsed, want) {
+			t.Errorf("CollapseShardIDs: want=%v current=%v", want, collapsed)
+		}
+	})
+}
+
+func Test_mergeShardFields(t *testing.T) {
+	var (
+		latest = []*FieldSchema{
+			{Name: "id", Type: bigquery.IntegerFieldType, Mode: FieldModeRequired},
+			{Name: "name", Type: bigquery.StringFieldType, Mode: FieldModeRequired},
+			{Name: "amount", Type: bigquery.NumericFieldType},
+			{Name: "payload", Type: bigquery.RecordFieldType, Fields: []*FieldSchema{
+				{Name: "key", Type: bigquery.StringFieldType},
+			}},
+			{Name: "added", Type: bigquery.StringFieldType, Mode: FieldModeRequired},
+		}
+		older = []*FieldSchema{
+			{Name: "id", Type: bigquery.IntegerFieldType, Mode: FieldModeRequired},
+			{Name: "name", Type: bigquery.StringFieldType, Mode: FieldModeNullable},
+			{Name: "amount", Type: bigquery.FloatFieldType},
+			{Name: "payload", Type: bigquery.RecordFieldType, Fields: []*FieldSchema{
+				{Name: "key", Type: bigquery.StringFieldType},
+				{Name: "value", Type: bigquery.StringFieldType, Mode: FieldModeRequired},
+			}},
+			{Name: "removed", Type: bigquery.StringFieldType, Mode: FieldModeRepeated},
+		}
+		wantMerged = []*FieldSchema{
+			{Name: "id", Type: bigquery.IntegerFieldType, Mode: FieldModeRequired},
+			{Name: "name", Type: bigquery.StringFieldType, Mode: FieldModeNullable},
+			{Name: "amount", Type: bigquery.NumericFieldType},
+			{Name: "payload", Type: bigquery.RecordFieldType, Fields: []*FieldSchema{
+				{Name: "key", Type: bigquery.StringFieldType},
+				{Name: "value", Type: bigquery.StringFieldType, Mode: FieldModeNullable},
+			}},
+			{Name: "added", Type: bigquery.StringFieldType, Mode: FieldModeNullable},
+			{Name: "removed", Type: bigquery.StringFieldType, Mode: FieldModeRepeated},
+		}
+		wantDiffs = []string{
+			"`name` is REQUIRED and NULLABLE in the older shard",
+			"`amount` is NUMERIC and FLOAT in the older shard (incompatible, the latest is used)",
+			"`payload.value` is only in the older shard",
+			"`added` is not in the older shard",
+			"`removed` is only in the older shard",
+		}
+	)
+
+	merged, diffs := mergeShardFields(latest, older, "")
+	if !reflect.DeepEqual(merged, wantMerged) {
+		for i := range merged {
+			t.Errorf("mergeShardFields: %d: %#v", i, merged[i])
+		}
+	}
+	if !reflect.DeepEqual(diffs, wantDiffs) {
+		t.Errorf("mergeShardFields: want=%q current=%q", wantDiffs, diffs)
+	}
+	if latest[1].Mode != FieldModeRequired || latest[4].Mode != FieldModeRequired {
+		t.Error("mergeShardFields: the fields of the latest shard are modified")
+	}
+
+	if _, diffs := mergeShardFields(latest, latest, ""); len(diffs) != 0 {
+		t.Errorf("mergeShardFields: same schema: diffs=%q", diffs)
+	}
+}
+
+func Test_Generator_Generate_CollapseShards(t *testing.T) {
+	newSource := func(olderAmountType bigquery.FieldType) SchemaSource {
+		return NewMemorySource(
+			&TableSchema{TableID: "events_20240102", FullID: "project:dataset.events_20240102", Description: "latest", Fields: []*FieldSchema{
+				{Name: "id", Type: bigquery.IntegerFieldType, Mode: FieldModeRequired},
+				{Name: "amount", Type: bigquery.NumericFieldType},
+			}},
+			&TableSchema{TableID: "events_20240101", FullID: "project:dataset.events_20240101", Description: "older", Fields: []*FieldSchema{
+				{Name: "id", Type: bigquery.IntegerFieldType, Mode: FieldModeRequired},
+				{Name: "amount", Type: olderAmountType},
+			}},
+			&TableSchema{TableID: "users", Fields: []*FieldSchema{{Name: "id", Type: bigquery.IntegerFieldType}}},
+		)
+	}
+
+	t.Run("正常系_ShardModeMerge", func(t *testing.T) {
+		generatedCode, err := newTestGenerator(t, Options{CollapseShards: ShardModeMerge}).Generate(context.Background(), newSource(bigquery.FloatFieldType))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"// Events is BigQuery Table `project:dataset.events_*` schema struct.\n// Description: latest\n",
+			"\tAmount *big.Rat `bigquery:\"amount\"`\n",
+			"type Users struct",
+		} {
+			if !strings.Contains(string(generatedCode), want) {
+				t.Error("Generate: want=`" + want + "` current=`" + string(generatedCode) + "`")
+			}
+		}
+		if strings.Contains(string(generatedCode), "Events20240101") || strings.Contains(string(generatedCode), "Events20240102") {
+			t.Error("Generate: shards are not collapsed: " + string(generatedCode))
+		}
+	})
+
+	t.Run("正常系_ShardModeStrict_same_schema", func(t *testing.T) {
+		if _, err := newTestGenerator(t, Options{CollapseShards: ShardModeStrict}).Generate(context.Background(), newSource(bigquery.NumericFieldType)); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("異常系_ShardModeStrict", func(t *testing.T) {
+		if _, err := newTestGenerator(t, Options{CollapseShards: ShardModeStrict}).Generate(context.Background(), newSource(bigquery.FloatFieldType)); !errors.Is(err, ErrShardSchemaMismatch) {
+			t.Error(err)
+		}
+	})
+
+	t.Run("正常系_column_override_of_collapsed_table", func(t *testing.T) {
+		g := newTestGenerator(t, Options{CollapseShards: ShardModeMerge, ColumnNames: map[string]string{"events.id": "EventID"}})
+		generatedCode, err := g.Generate(context.Background(), newSource(bigquery.NumericFieldType))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(generatedCode), "EventID int64") {
+			t.Error("Generate: " + string(generatedCode))
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package bqschemagen
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+)
+
+// SchemaSource reads the table schemas that the code is generated from.
+// Table is called concurrently by Generator, so it must be safe for concurrent use.
+type SchemaSource interface {
+	// TableIDs returns the IDs of the tables in the source sorted.
+	TableIDs(ctx context.Context) (tableIDs []string, err error)
+	// Table returns the schema of the table of tableID.
+	Table(ctx context.Context, tableID string) (table *TableSchema, err error)
+}
+
+// bigquerySource reads the table schemas of a dataset from the BigQuery API.
+type bigquerySource struct {
+	dataset *bigquery.Dataset
+}
+
+// NewBigQuerySource returns the SchemaSource that reads the tables in dataset of the project of client from the BigQuery API.
+func NewBigQuerySource(client *bigquery.Client, dataset string) SchemaSource {
+	return &bigquerySource{dataset: client.Dataset(dataset)}
+}
+
+// NewBigQueryDatasetSource returns the SchemaSource that reads the tables in dataset of project from the BigQuery API.
+// project may differ from the project of client, which the API requests are billed to.
+func NewBigQueryDatasetSource(client *bigquery.Client, project, dataset string) SchemaSource {
+	return &bigquerySource{dataset: client.DatasetInProject(project, dataset)}
+}
+
+func (s *bigquerySource) TableIDs(ctx context.Context) (tableIDs []string, err error) {
+	tables, err := getAllTables(ctx, s.dataset)
+	if err != nil {
+		return nil, fmt.Errorf("getAllTables: %w", err)
+	}
+
+	for _, table := range tables {
+		tableIDs = append(tableIDs, table.TableID)
+	}
+	sort.Strings(tableIDs)
+
+	return tableIDs, nil
+}
+
+func (s *bigquerySource) Table(ctx context.Context, tableID string) (table *TableSchema, err error) {
+	md, err := s.dataset.Table(tableID).Metadata(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("table.Metadata: %w", err)
+	}
+	return newTableSchema(tableID, md), nil
+}
+
+func getAllTables(ctx context.Context, dataset *bigquery.Dataset) (tables []*bigquery.Table, err error) {
+	tableIterator := dataset.Tables(ctx)
+	for {
+		var table *bigquery.Table
+		table, err = tableIterator.Next()
+		if err != nil {
+			if err == iterator.Done {
+				break
+			}
+			return nil, fmt.Errorf("tableIterator.Next: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// schemaFileSource reads the table schemas from local table schema JSON files.
+type schemaFileSource struct {
+	schemaFiles []schemaFile
+	project     string
+	dataset     string
+}
+
+// NewSchemaDirSource returns the SchemaSource that reads the table schema JSON files (`bq show --schema --format=prettyjson`) in dir.
+// The table IDs are the file names without `.json`.
+// project and dataset are used for the table full IDs in the comments if the files do not have them, and may be empty.
+func NewSchemaDirSource(dir, project, dataset string) (source SchemaSource, err error) {
+	schemaFiles, err := listSchemaFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("listSchemaFiles: %w", err)
+	}
+	return newSchemaFileSource(schemaFiles, project, dataset), nil
+}
+
+// NewSchemaManifestSource returns the SchemaSource that reads the table schema JSON files listed in the manifest JSON file at path,
+// which maps the table IDs to the file paths relative to the manifest (e.g. `{"stories": "schema/stories.json"}`).
+// project and dataset are used like NewSchemaDirSource.
+func NewSchemaManifestSource(path, project, dataset string) (source SchemaSource, err error) {
+	schemaFiles, err := readSchemaManifest(path)
+	if err != nil {
+		return nil, fmt.Errorf("readSchemaManifest: %w", err)
+	}
+	return newSchemaFileSource(schemaFiles, project, dataset), nil
+}
+
+// newSchemaFileSource returns the SchemaSource that reads schemaFiles for NewSchemaDirSource and NewSchemaManifestSource.
+func newSchemaFileSource(schemaFiles []schemaFile, project, dataset string) *schemaFileSource {
+	return &schemaFileSource{schemaFiles: schemaFiles, project: project, dataset: dataset}
+}
+
+func (s *schemaFileSource) TableIDs(_ context.Context) (tableIDs []string, err error) {
+	for _, file := range s.schemaFiles {
+		tableIDs = append(tableIDs, file.tableID)
+	}
+	sort.Strings(tableIDs)
+	return tableIDs, nil
+}
+
+func (s *schemaFileSource) Table(_ context.Context, tableID string) (table *TableSchema, err error) {
+	for _, file := range s.schemaFiles {
+		if file.tableID != tableID {
+			continue
+		}
+
+		var md *bigquery.TableMetadata
+		md, err = readSchemaFile(file.path)
+		if err != nil {
+			return nil, fmt.Errorf("readSchemaFile: %w", err)
+		}
+		if md.FullID == "" {
+			md.FullID = tableFullID(s.project, s.dataset, tableID)
+		}
+		return newTableSchema(tableID, md), nil
+	}
+	return nil, fmt.Errorf("schema file not found: tableID=%s", tableID)
+}
+
+// memorySource is the SchemaSource of the table schemas in memory.
+type memorySource struct {
+	tables map[string]*TableSchema
+}
+
+// NewMemorySource returns the SchemaSource of tables, e.g. a fake of the BigQuery API in tests.
+// The tables are keyed by TableSchema.TableID, and the last one wins if the same table ID is given more than once.
+func NewMemorySource(tables ...*TableSchema) SchemaSource {
+	s := &memorySource{tables: make(map[string]*TableSchema, len(tables))}
+	for _, table := range tables {
+		s.tables[table.TableID] = table
+	}
+	return s
+}
+
+func (s *memorySource) TableIDs(_ context.Context) (tableIDs []string, err error) {
+	for tableID := range s.tables {
+		tableIDs = append(tableIDs, tableID)
+	}
+	sort.Strings(tableIDs)
+	return tableIDs, nil
+}
+
+func (s *memorySource) Table(_ context.Context, tableID string) (table *TableSchema, err error) {
+	table, ok := s.tables[tableID]
+	if !ok {
+		return nil, fmt.Errorf("table not found: tableID=%s", tableID)
+	}
+	return table, nil
+}
+
+// NewDDLSource returns the SchemaSource of the tables created by the CREATE TABLE statements in the DDL files that match the glob patterns,
+// to which the ALTER TABLE and DROP TABLE statements are applied in file name order.
+// The table defined last wins if the same table is created more than once, unless it is CREATE TABLE IF NOT EXISTS.
+// project and dataset are used for the table full IDs in the comments if the statements do not qualify the table names, and may be empty.
+// logger receives the warnings of the skipped statements like Options.Logger, and log.Default() is used if it is nil.
+func NewDDLSource(patterns []string, project, dataset string, logger *log.Logger) (source SchemaSource, err error) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	tables, err := readDDLFiles(patterns, logger)
+	if err != nil {
+		return nil, fmt.Errorf("readDDLFiles: %w", err)
+	}
+	return newDDLSource(tables, project, dataset), nil
+}
+
+// newDDLSource returns the SchemaSource of the tables parsed from CREATE TABLE statements for NewDDLSource.
+func newDDLSource(ddlTables []ddlTable, project, dataset string) SchemaSource {
+	tables := make([]*TableSchema, len(ddlTables))
+	for i, ddl := range ddlTables {
+		tables[i] = newTableSchema(ddl.tableID, ddl.md)
+		if tables[i].FullID == "" {
+			tables[i].FullID = tableFullID(project, dataset, ddl.tableID)
+		}
+	}
+	return NewMemorySource(tables...)
+}
@@ -0,0 +1,176 @@
+package bqschemagen
+
+import (
+	"context"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func Test_bigquerySource(t *testing.T) {
+	t.Run("正常系_testPublicDataProjectID_testSupportedDatasetID", func(t *testing.T) {
+		if os.Getenv(GOOGLE_APPLICATION_CREDENTIALS) == "" {
+			t.Skip("WARN: " + GOOGLE_APPLICATION_CREDENTIALS + " is not set")
+		}
+
+		var (
+			ctx       = context.Background()
+			client, _ = bigquery.NewClient(ctx, testPublicDataProjectID)
+			source    = NewBigQueryDatasetSource(client, testPublicDataProjectID, testSupportedDatasetID)
+		)
+
+		tableIDs, err := source.TableIDs(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tableID := range tableIDs {
+			table, err := source.Table(ctx, tableID)
+			if err != nil {
+				t.Error(err)
+				continue
+			}
+			if table.TableID != tableID || len(table.Fields) == 0 {
+				t.Errorf("source.Table: table=%#v", table)
+			}
+		}
+	})
+
+	t.Run("異常系_testProjectNotFound_testDatasetNotFound", func(t *testing.T) {
+		t.Setenv(GOOGLE_APPLICATION_CREDENTIALS, testGoogleApplicationCredentials)
+
+		var (
+			ctx         = context.Background()
+			ngClient, _ = bigquery.NewClient(ctx, testProjectNotFound)
+			source      = NewBigQuerySource(ngClient, testDatasetNotFound)
+		)
+
+		if _, err := source.TableIDs(ctx); err == nil {
+			t.Error(err)
+		}
+		if _, err := source.Table(ctx, "notfound"); err == nil {
+			t.Error(err)
+		}
+	})
+}
+
+func Test_getAllTables(t *testing.T) {
+	t.Run("正常系_testPublicDataProjectID_testSupportedDatasetID", func(t *testing.T) {
+
+		if os.Getenv(GOOGLE_APPLICATION_CREDENTIALS) == "" {
+			t.Skip("WARN: " + GOOGLE_APPLICATION_CREDENTIALS + " is not set")
+		}
+
+		var (
+			ctx         = context.Background()
+			okClient, _ = bigquery.NewClient(ctx, testPublicDataProjectID)
+		)
+
+		if _, err := getAllTables(ctx, okClient.Dataset(testSupportedDatasetID)); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("異常系_testProjectNotFound_testDatasetNotFound", func(t *testing.T) {
+		t.Setenv(GOOGLE_APPLICATION_CREDENTIALS, testGoogleApplicationCredentials)
+
+		var (
+			ctx         = context.Background()
+			ngClient, _ = bigquery.NewClient(ctx, testProjectNotFound)
+		)
+
+		if _, err := getAllTables(ctx, ngClient.Dataset(testDatasetNotFound)); err == nil {
+			t.Error(err)
+		}
+	})
+}
+
+func Test_schemaFileSource(t *testing.T) {
+	t.Run("正常系_testSchemaDir", func(t *testing.T) {
+		schemaFiles, err := listSchemaFiles(testSchemaDir)
+		if err != nil {
+			t.Error(err)
+		}
+
+		generatedCode, err := newTestGenerator(t, Options{}).Generate(context.Background(), newSchemaFileSource(schemaFiles, "bigquery-public-data", "hacker_news"))
+		if err != nil {
+			t.Error(err)
+		}
+		for _, want := range []string{
+			"// Events is BigQuery Table `example-project:analytics.events` schema struct.\n",
+			"\tPayload *EventsPayload `bigquery:\"payload\"`\n",
+			"\tAmount *big.Rat `bigquery:\"amount\"` // BIGNUMERIC: precision 76.76 (the 77th digit is partial), scale 38\n",
+			"// Stories is BigQuery Table `bigquery-public-data:hacker_news.stories` schema struct.\n",
+			"\tTimeTs time.Time `bigquery:\"time_ts\"`\n",
+			"\tTags   []string  `bigquery:\"tags\"`\n",
+		} {
+			if !strings.Contains(string(generatedCode), want) {
+				t.Error("Generate: want=`" + want + "` current=`" + string(generatedCode) + "`")
+			}
+		}
+	})
+
+	t.Run("異常系_testSchemaFileNotFound", func(t *testing.T) {
+		source := newSchemaFileSource([]schemaFile{{tableID: "notfound", path: testSchemaFileNotFound}}, testEmptyString, testEmptyString)
+		if _, err := source.Table(context.Background(), "notfound"); err == nil {
+			t.Error(err)
+		}
+		if _, err := source.Table(context.Background(), "stories"); err == nil {
+			t.Error(err)
+		}
+	})
+}
+
+func Test_memorySource(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		users  = &TableSchema{TableID: "users"}
+		source = NewMemorySource(users, &TableSchema{TableID: "events"})
+	)
+
+	tableIDs, err := source.TableIDs(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+	if want := []string{"events", "users"}; !reflect.DeepEqual(tableIDs, want) {
+		t.Errorf("source.TableIDs: want=%v current=%v", want, tableIDs)
+	}
+
+	if table, err := source.Table(ctx, "users"); err != nil || table != users {
+		t.Errorf("source.Table: table=%#v, err=%v", table, err)
+	}
+	if _, err := source.Table(ctx, "notfound"); err == nil {
+		t.Error(err)
+	}
+}
+
+func Test_newDDLSource(t *testing.T) {
+	tables, err := readDDLFiles([]string{testDDLPattern}, log.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := newDDLSource(tables, "project", "dataset")
+	users, err := source.Table(context.Background(), "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users.FullID != "testdataset.users" || users.Fields[0].Mode != FieldModeRequired || users.Fields[2].Fields[1].Mode != FieldModeRepeated {
+		t.Errorf("source.Table: users=%#v", users)
+	}
+
+	tables, err = parseDDL("CREATE TABLE t (a INT64)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := newDDLSource(tables, "project", "dataset").Table(context.Background(), "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.FullID != "project:dataset.t" {
+		t.Errorf("source.Table: table=%#v", table)
+	}
+}
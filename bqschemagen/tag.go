@@ -0,0 +1,197 @@
+package bqschemagen
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// TagNaming is how a column name is converted into the name in a struct tag.
+type TagNaming string
+
+const (
+	// TagNamingOriginal uses the column name as is.
+	TagNamingOriginal TagNaming = "original"
+	// TagNamingSnake converts `userId` into `user_id`.
+	TagNamingSnake TagNaming = "snake"
+	// TagNamingCamel converts `user_id` into `userID`, upper-casing Options.Initialisms.
+	TagNamingCamel TagNaming = "camel"
+)
+
+// defaultTagTemplates are the templates of the values of the well-known tags.
+// NOTE(ginokent): parquet is the tag of github.com/parquet-go/parquet-go.
+var defaultTagTemplates = map[string]string{
+	"json":    `{{.Name}}{{if .Nullable}},omitempty{{end}}`,
+	"db":      `{{.Name}}`,
+	"avro":    `{{.Name}}`,
+	"parquet": `{{.Name}}{{if .Nullable}},optional{{end}}`,
+}
+
+// defaultTagTemplate is the template of the value of the other tags.
+const defaultTagTemplate = `{{.Name}}`
+
+// TagOption is a struct tag added to the fields after the `bigquery` tag.
+type TagOption struct {
+	// Key is the key of the tag, such as json, db, avro, parquet or any other key.
+	Key string
+	// Naming is TagNamingOriginal by default.
+	Naming TagNaming
+	// Template is the text/template of the value of the tag executed with TagData, which can call the funcs of Generator.TemplateFuncs.
+	// By default, json is `{{.Name}}` with `,omitempty` for the NULLABLE columns, parquet is `{{.Name}}` with `,optional` for them,
+	// and the other keys are `{{.Name}}`.
+	Template string
+}
+
+// TagData is the data of TagOption.Template.
+type TagData struct {
+	// Name is the column name converted by TagOption.Naming, which is suffixed following Options.Collision if another column of the struct has the same name.
+	Name   string
+	Schema *FieldSchema
+	// Nullable is true for the NULLABLE columns, which may be NULL.
+	Nullable bool
+}
+
+// tagTemplate is a TagOption with the parsed template.
+type tagTemplate struct {
+	key      string
+	naming   TagNaming
+	template *template.Template
+}
+
+// validateTagOptions returns an error if a tag has an invalid key or an unknown naming.
+func validateTagOptions(tags []TagOption) (err error) {
+	keys := map[string]bool{"bigquery": true}
+	for _, tag := range tags {
+		// NOTE(ginokent): ref. https://pkg.go.dev/reflect#StructTag
+		if tag.Key == "" || strings.IndexFunc(tag.Key, func(r rune) bool { return r <= ' ' || r == ':' || r == '"' || r == 0x7f }) >= 0 {
+			return fmt.Errorf("invalid tag key: %q", tag.Key)
+		}
+		if keys[tag.Key] {
+			return fmt.Errorf("duplicate tag key: %s", tag.Key)
+		}
+		keys[tag.Key] = true
+
+		switch tag.Naming {
+		case TagNamingOriginal, TagNamingSnake, TagNamingCamel:
+		default:
+			return fmt.Errorf("invalid tag naming: %s:%s", tag.Key, tag.Naming)
+		}
+	}
+	return nil
+}
+
+// newTagTemplates parses the templates of Options.Tags.
+func (g *Generator) newTagTemplates() (tagTemplates []tagTemplate, err error) {
+	for _, tag := range g.opts.Tags {
+		text := tag.Template
+		if text == "" {
+			text = defaultTagTemplate
+			if defaultText, ok := defaultTagTemplates[tag.Key]; ok {
+				text = defaultText
+			}
+		}
+
+		var tmpl *template.Template
+		tmpl, err = template.New(tag.Key).Funcs(g.TemplateFuncs()).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("template.Parse: tag=%s, %w", tag.Key, err)
+		}
+		tagTemplates = append(tagTemplates, tagTemplate{key: tag.Key, naming: tag.Naming, template: tmpl})
+	}
+	return tagTemplates, nil
+}
+
+// newTagScopes returns the empty scopes of the names of Options.Tags in the struct named structName, keyed by the tag key.
+func (g *Generator) newTagScopes(structName string) (tagScopes map[string]*identifierScope) {
+	tagScopes = make(map[string]*identifierScope, len(g.tagTemplates))
+	for _, tagTemplate := range g.tagTemplates {
+		tagScopes[tagTemplate.key] = g.newIdentifierScope(tagTemplate.key + " tag of struct " + structName)
+	}
+	return tagScopes
+}
+
+// fieldTags returns the struct tags of fieldSchema, which are the `bigquery` tag and the tags of Options.Tags.
+// The names of the tags are declared in tagScopes of newTagScopes, so that two columns never get the same name of a tag (e.g. `user_id` and `userId` in json:camel).
+func (g *Generator) fieldTags(fieldSchema *FieldSchema, tagScopes map[string]*identifierScope) (tags []Tag, err error) {
+	tags = []Tag{{Key: "bigquery", Value: fieldSchema.Name}}
+	for _, tagTemplate := range g.tagTemplates {
+		var name string
+		name, err = tagScopes[tagTemplate.key].declare(g.tagName(fieldSchema.Name, tagTemplate.naming), fieldSchema.Name)
+		if err != nil {
+			return nil, fmt.Errorf("tagScope.declare: %w", err)
+		}
+		data := TagData{Name: name, Schema: fieldSchema, Nullable: fieldSchema.nullable()}
+
+		var b strings.Builder
+		if err = tagTemplate.template.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("template.Execute: tag=%s, %w", tagTemplate.key, err)
+		}
+		// NOTE(ginokent): the struct tags are raw string literals, which can not contain a backquote.
+		if strings.Contains(b.String(), "`") {
+			return nil, fmt.Errorf("tag value can not contain a backquote: tag=%s, %s", tagTemplate.key, b.String())
+		}
+		tags = append(tags, Tag{Key: tagTemplate.key, Value: b.String()})
+	}
+	return tags, nil
+}
+
+// tagName converts the column name into the name in a struct tag following naming.
+func (g *Generator) tagName(name string, naming TagNaming) string {
+	switch naming {
+	case TagNamingSnake:
+		return snakeCase(name)
+	case TagNamingCamel:
+		return g.lowerCamelCase(name)
+	default:
+		return name
+	}
+}
+
+// ParseTagOptions parses comma-separated key[:naming][=template] entries into Options.Tags (e.g. `json:camel,db:snake,avro`).
+// The commas in `{{ }}` of the templates do not separate the entries.
+func ParseTagOptions(tagsCSV string) (tags []TagOption, err error) {
+	for _, entry := range splitTagEntries(tagsCSV) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		var tag TagOption
+		head := entry
+		if i := strings.Index(entry, "="); i >= 0 {
+			head, tag.Template = entry[:i], entry[i+1:]
+			if tag.Template == "" {
+				return nil, fmt.Errorf("template is empty: %s", entry)
+			}
+		}
+		tag.Key, tag.Naming = head, TagNamingOriginal
+		if i := strings.Index(head, ":"); i >= 0 {
+			tag.Key, tag.Naming = head[:i], TagNaming(head[i+1:])
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = validateTagOptions(tags); err != nil {
+		return nil, fmt.Errorf("validateTagOptions: %w", err)
+	}
+	return tags, nil
+}
+
+// splitTagEntries splits tagsCSV at the commas that are not in `{{ }}`.
+func splitTagEntries(tagsCSV string) (entries []string) {
+	depth, start := 0, 0
+	for i := 0; i < len(tagsCSV); i++ {
+		switch {
+		case strings.HasPrefix(tagsCSV[i:], "{{"):
+			depth++
+			i++
+		case strings.HasPrefix(tagsCSV[i:], "}}") && depth > 0:
+			depth--
+			i++
+		case tagsCSV[i] == ',' && depth == 0:
+			entries = append(entries, tagsCSV[start:i])
+			start = i + 1
+		}
+	}
+	return append(entries, tagsCSV[start:])
+}
@@ -0,0 +1,115 @@
+package bqschemagen
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func Test_ParseTagOptions(t *testing.T) {
+	t.Run("正常系", func(t *testing.T) {
+		tags, err := ParseTagOptions(`json:camel, db:snake,avro,validate={{if .Nullable}}omitempty{{else}}{{printf "%s,%s" "required" "min=1"}}{{end}}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []TagOption{
+			{Key: "json", Naming: TagNamingCamel},
+			{Key: "db", Naming: TagNamingSnake},
+			{Key: "avro", Naming: TagNamingOriginal},
+			{Key: "validate", Naming: TagNamingOriginal, Template: `{{if .Nullable}}omitempty{{else}}{{printf "%s,%s" "required" "min=1"}}{{end}}`},
+		}
+		if !reflect.DeepEqual(tags, want) {
+			t.Errorf("ParseTagOptions: want=%#v current=%#v", want, tags)
+		}
+	})
+
+	for _, tagsCSV := range []string{"json:kebab", "json,json", "bigquery", ":camel", "json=", `a"b`} {
+		tagsCSV := tagsCSV
+		t.Run("異常系_"+tagsCSV, func(t *testing.T) {
+			if _, err := ParseTagOptions(tagsCSV); err == nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_Generator_Generate_Tags(t *testing.T) {
+	source := NewMemorySource(&TableSchema{TableID: "users", Fields: []*FieldSchema{
+		{Name: "userId", Type: bigquery.IntegerFieldType, Mode: FieldModeRequired},
+		{Name: "display_name", Type: bigquery.StringFieldType},
+		{Name: "tags", Type: bigquery.StringFieldType, Mode: FieldModeRepeated},
+	}})
+
+	t.Run("正常系", func(t *testing.T) {
+		g := newTestGenerator(t, Options{Tags: []TagOption{
+			{Key: "json", Naming: TagNamingCamel},
+			{Key: "db", Naming: TagNamingSnake},
+			{Key: "avro"},
+			{Key: "parquet", Naming: TagNamingSnake},
+			{Key: "validate", Template: `{{if .Nullable}}omitempty{{else}}required{{end}}`},
+			{Key: "csv", Naming: TagNamingSnake, Template: `{{upper .Name}}`},
+		}})
+		generatedCode, err := g.Generate(context.Background(), source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"`bigquery:\"userId\" json:\"userID\" db:\"user_id\" avro:\"userId\" parquet:\"user_id\" validate:\"required\" csv:\"USER_ID\"`",
+			"`bigquery:\"display_name\" json:\"displayName,omitempty\" db:\"display_name\" avro:\"display_name\" parquet:\"display_name,optional\" validate:\"omitempty\" csv:\"DISPLAY_NAME\"`",
+			"`bigquery:\"tags\" json:\"tags\" db:\"tags\" avro:\"tags\" parquet:\"tags\" validate:\"required\" csv:\"TAGS\"`",
+		} {
+			if !strings.Contains(string(generatedCode), want) {
+				t.Error("Generate: want=`" + want + "` current=`" + string(generatedCode) + "`")
+			}
+		}
+	})
+
+	t.Run("正常系_collision", func(t *testing.T) {
+		source := NewMemorySource(&TableSchema{TableID: "users", Fields: []*FieldSchema{
+			{Name: "user_id", Type: bigquery.IntegerFieldType},
+			{Name: "userId", Type: bigquery.IntegerFieldType},
+			{Name: "profile", Type: bigquery.RecordFieldType, Fields: []*FieldSchema{{Name: "user_id", Type: bigquery.IntegerFieldType}}},
+		}})
+		generatedCode, err := newTestGenerator(t, Options{Tags: []TagOption{{Key: "json", Naming: TagNamingCamel}}}).Generate(context.Background(), source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"\tUserID   int64         `bigquery:\"user_id\" json:\"userID,omitempty\"`\n",
+			"\tUserID_2 int64         `bigquery:\"userId\" json:\"userID_2,omitempty\"`\n",
+			"type UsersProfile struct {\n\tUserID int64 `bigquery:\"user_id\" json:\"userID,omitempty\"`\n}\n",
+		} {
+			if !strings.Contains(string(generatedCode), want) {
+				t.Error("Generate: want=`" + want + "` current=`" + string(generatedCode) + "`")
+			}
+		}
+	})
+
+	t.Run("異常系_collision", func(t *testing.T) {
+		source := NewMemorySource(&TableSchema{TableID: "users", Fields: []*FieldSchema{
+			{Name: "user_id", Type: bigquery.IntegerFieldType},
+			{Name: "USER_ID", Type: bigquery.IntegerFieldType},
+		}})
+		g := newTestGenerator(t, Options{Collision: CollisionError, ColumnNames: map[string]string{"users.USER_ID": "UpperUserID"}, Tags: []TagOption{{Key: "db", Naming: TagNamingSnake}}})
+		if _, err := g.Generate(context.Background(), source); !errors.Is(err, ErrIdentifierCollision) || !strings.Contains(err.Error(), "db tag of struct Users") {
+			t.Error(err)
+		}
+	})
+
+	t.Run("異常系_backquote", func(t *testing.T) {
+		g := newTestGenerator(t, Options{Strict: true, Tags: []TagOption{{Key: "doc", Template: "`{{.Name}}`"}}})
+		if _, err := g.Generate(context.Background(), source); err == nil || !strings.Contains(err.Error(), "backquote") {
+			t.Error(err)
+		}
+	})
+
+	t.Run("異常系_template", func(t *testing.T) {
+		if _, err := New(Options{Tags: []TagOption{{Key: "doc", Template: "{{.Name"}}}); err == nil {
+			t.Error(err)
+		}
+	})
+}
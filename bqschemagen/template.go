@@ -0,0 +1,131 @@
+package bqschemagen
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// DefaultTemplate is the text/template of the generated code, which defines the templates "file", "table", "structs", "struct" and "field".
+// Options.Template can redefine any of them, and the templates can call the funcs of TemplateFuncs.
+//
+//go:embed default.tmpl
+var DefaultTemplate string
+
+// FileData is the data of the "file" template.
+type FileData struct {
+	PackageName string
+	// GoGenerate is true for the file that has the go:generate directive, which is only one file of the package.
+	GoGenerate bool
+	// Imports are the unique import paths of the types of the tables.
+	Imports []string
+	Tables  []*TableData
+}
+
+// TableData is the data of the "table" template.
+type TableData struct {
+	Table *TableSchema
+	// StructName is the Go name of the struct of the table, which is prefixed with the dataset by Generator.GenerateDatasets.
+	StructName string
+	// Description is the lines of the comment of Table.Description without `//`, or empty.
+	Description []string
+	// Structs are the struct of the table and the structs of the RECORD fields in the depth-first order.
+	Structs []*StructData
+	// Imports are the import paths of the types of the fields, which may have duplicates.
+	Imports []string
+	// Code is the code generated by the "table" template, which is set before the "file" template is executed.
+	Code string
+}
+
+// StructData is the data of the "struct" template.
+type StructData struct {
+	// Name is the Go name of the struct.
+	Name string
+	// ColumnPath is the dot-separated path of the table and the RECORD field (e.g. `events` or `events.payload`).
+	ColumnPath string
+	// Record is the RECORD field of a nested struct, or nil for the struct of the table.
+	Record *FieldSchema
+	// Parent is the Go name of the struct that has Record, or empty for the struct of the table.
+	Parent string
+	Fields []*FieldData
+}
+
+// FieldData is the data of the "field" template.
+type FieldData struct {
+	// Name is the Go name of the field.
+	Name string
+	// Type is the Go type of the field as written in the generated code (e.g. `[]*big.Rat`).
+	Type string
+	// ImportPath is the import path of Type, or empty.
+	ImportPath string
+	// ColumnPath is the dot-separated path of the column (e.g. `events.payload.user_id`).
+	ColumnPath string
+	Schema     *FieldSchema
+	Tags       []Tag
+	// Doc is the lines of the doc comment of the field without `//`, which are the lines of the column description and the policy tags, or empty.
+	Doc []string
+	// Comment is the BigQuery type details that Type can not express (e.g. `RANGE<DATE>`), or empty.
+	Comment string
+}
+
+// Tag is a key of the struct tag of a field, such as `bigquery:"user_id"`.
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Tag returns the struct tag of the field without the backquotes, such as `bigquery:"user_id"`.
+func (f *FieldData) Tag() string {
+	tags := make([]string, len(f.Tags))
+	for i, tag := range f.Tags {
+		tags[i] = tag.Key + ":" + strconv.Quote(tag.Value)
+	}
+	return strings.Join(tags, " ")
+}
+
+// TemplateFuncs returns the funcs that the templates of g can call:
+//
+//	goName     converts a BigQuery name into a Go name following Options.NamingMode (e.g. `user_id` -> `UserID`)
+//	camelCase  converts a BigQuery name into lowerCamelCase (e.g. `user_id` -> `userID`)
+//	snakeCase  converts a BigQuery name into snake_case (e.g. `userId` -> `user_id`)
+//	lower, upper, quote (strconv.Quote), join (strings.Join)
+//	generatedCodeComment returns GeneratedCodeComment
+func (g *Generator) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"goName":               g.toGoName,
+		"camelCase":            g.lowerCamelCase,
+		"snakeCase":            snakeCase,
+		"lower":                strings.ToLower,
+		"upper":                strings.ToUpper,
+		"quote":                strconv.Quote,
+		"join":                 strings.Join,
+		"generatedCodeComment": func() string { return GeneratedCodeComment },
+	}
+}
+
+// newTemplate parses DefaultTemplate and Options.Template, which redefines the default templates.
+func (g *Generator) newTemplate() (tmpl *template.Template, err error) {
+	tmpl, err = template.New("default").Funcs(g.TemplateFuncs()).Parse(DefaultTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("template.Parse: default: %w", err)
+	}
+	if g.opts.Template == "" {
+		return tmpl, nil
+	}
+
+	if _, err = tmpl.New("Template").Parse(g.opts.Template); err != nil {
+		return nil, fmt.Errorf("template.Parse: Template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// executeTemplate executes the template name with data.
+func (g *Generator) executeTemplate(name string, data interface{}) (generatedCode string, err error) {
+	var b strings.Builder
+	if err = g.template.ExecuteTemplate(&b, name, data); err != nil {
+		return "", fmt.Errorf("template.ExecuteTemplate: %w", err)
+	}
+	return b.String(), nil
+}
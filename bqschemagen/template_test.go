@@ -0,0 +1,75 @@
+package bqschemagen
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func Test_FieldData_Tag(t *testing.T) {
+	const (
+		// 正しい出力
+		testTag = `bigquery:"user_id" json:"userID,omitempty"`
+	)
+
+	field := &FieldData{Tags: []Tag{{Key: "bigquery", Value: "user_id"}, {Key: "json", Value: "userID,omitempty"}}}
+	if tag := field.Tag(); tag != testTag {
+		t.Error("Tag: want=`" + testTag + "` current=`" + tag + "`")
+	}
+}
+
+func Test_Generator_Generate_Template(t *testing.T) {
+	newSource := func() SchemaSource {
+		return NewMemorySource(&TableSchema{TableID: "users", FullID: "project:dataset.users", Fields: []*FieldSchema{
+			{Name: "userId", Type: bigquery.IntegerFieldType},
+			{Name: "profile", Type: bigquery.RecordFieldType, Fields: []*FieldSchema{
+				{Name: "display_name", Type: bigquery.StringFieldType},
+			}},
+		}})
+	}
+
+	t.Run("正常系_redefine_table_and_field", func(t *testing.T) {
+		const (
+			testTemplate = `{{define "table"}}{{template "structs" .Structs}}
+// TableName returns the BigQuery table ID of {{.StructName}}.
+func ({{.StructName}}) TableName() string { return {{quote .Table.TableID}} }
+{{end}}
+
+{{define "field"}}	{{.Name}} {{.Type}} ` + "`{{.Tag}} db:\"{{snakeCase .Schema.Name}}\" json:\"{{camelCase .Schema.Name}}\"`" + `
+{{end}}`
+		)
+
+		generatedCode, err := newTestGenerator(t, Options{Template: testTemplate}).Generate(context.Background(), newSource())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"func (Users) TableName() string { return \"users\" }\n",
+			"\tUserID  int64         `bigquery:\"userId\" db:\"user_id\" json:\"userID\"`\n",
+			"\tDisplayName string `bigquery:\"display_name\" db:\"display_name\" json:\"displayName\"`\n",
+			"type UsersProfile struct {\n",
+		} {
+			if !strings.Contains(string(generatedCode), want) {
+				t.Error("Generate: want=`" + want + "` current=`" + string(generatedCode) + "`")
+			}
+		}
+		if strings.Contains(string(generatedCode), "is BigQuery Table") {
+			t.Error("Generate: the table template is not redefined: " + string(generatedCode))
+		}
+	})
+
+	t.Run("異常系_parse", func(t *testing.T) {
+		if _, err := New(Options{Template: `{{define "table"}}{{.StructName}`}); err == nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("異常系_execute", func(t *testing.T) {
+		g := newTestGenerator(t, Options{Template: `{{define "file"}}{{.NotFound}}{{end}}`})
+		if _, err := g.Generate(context.Background(), newSource()); err == nil || !strings.Contains(err.Error(), "NotFound") {
+			t.Error(err)
+		}
+	})
+}
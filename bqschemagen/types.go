@@ -0,0 +1,158 @@
+package bqschemagen
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+)
+
+// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L216
+var typeOfByteSlice = reflect.TypeOf([]byte{})
+
+// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/params.go#L81-L87
+var (
+	typeOfDate     = reflect.TypeOf(civil.Date{})
+	typeOfTime     = reflect.TypeOf(civil.Time{})
+	typeOfDateTime = reflect.TypeOf(civil.DateTime{})
+	typeOfGoTime   = reflect.TypeOf(time.Time{})
+	typeOfRat      = reflect.TypeOf(&big.Rat{})
+)
+
+// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/bigquery/v1.85.0/bigquery/params.go#L100
+var typeOfRangeValue = reflect.TypeOf(&bigquery.RangeValue{})
+
+// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/bigquery/v1.85.0/bigquery/intervalvalue.go#L34
+var typeOfIntervalValue = reflect.TypeOf(&bigquery.IntervalValue{})
+
+// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/nulls.go
+var typeOfNulls = map[bigquery.FieldType]reflect.Type{
+	bigquery.StringFieldType:    reflect.TypeOf(bigquery.NullString{}),
+	bigquery.GeographyFieldType: reflect.TypeOf(bigquery.NullGeography{}),
+	bigquery.JSONFieldType:      reflect.TypeOf(bigquery.NullJSON{}),
+	bigquery.IntegerFieldType:   reflect.TypeOf(bigquery.NullInt64{}),
+	bigquery.FloatFieldType:     reflect.TypeOf(bigquery.NullFloat64{}),
+	bigquery.BooleanFieldType:   reflect.TypeOf(bigquery.NullBool{}),
+	bigquery.TimestampFieldType: reflect.TypeOf(bigquery.NullTimestamp{}),
+	bigquery.DateFieldType:      reflect.TypeOf(bigquery.NullDate{}),
+	bigquery.TimeFieldType:      reflect.TypeOf(bigquery.NullTime{}),
+	bigquery.DateTimeFieldType:  reflect.TypeOf(bigquery.NullDateTime{}),
+}
+
+// goType returns the Go type for bigqueryFieldType.
+// If nullable is true, the Go type follows Options.NullableMode.
+func (g *Generator) goType(bigqueryFieldType bigquery.FieldType, nullable bool) (goType GoType, err error) {
+	if nullable {
+		switch g.opts.NullableMode {
+		case NullableModeNull:
+			// NOTE(ginokent): BYTES, NUMERIC, BIGNUMERIC, RANGE and INTERVAL are nil for NULL, and the overridden types are used as is.
+			_, overridden := g.opts.TypeMap[bigqueryFieldType]
+			typeOfNull, ok := typeOfNulls[bigqueryFieldType]
+			if ok && !overridden && !(bigqueryFieldType == bigquery.JSONFieldType && g.opts.JSONType == JSONTypeRawMessage) {
+				return typeOf(typeOfNull), nil
+			}
+		case NullableModePointer:
+			goType, err = g.goType(bigqueryFieldType, false)
+			if err != nil {
+				return GoType{}, err
+			}
+			// NOTE(ginokent): []byte, *big.Rat, *bigquery.RangeValue and *bigquery.IntervalValue are already nil for NULL.
+			if !strings.HasPrefix(goType.Name, "*") && !strings.HasPrefix(goType.Name, "[]") {
+				goType.Name = "*" + goType.Name
+			}
+			return goType, nil
+		}
+	}
+
+	if override, ok := g.opts.TypeMap[bigqueryFieldType]; ok {
+		return override, nil
+	}
+
+	switch bigqueryFieldType {
+	// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L342-L343
+	case bigquery.BytesFieldType:
+		return typeOf(typeOfByteSlice), nil
+
+	// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L344-L358
+	case bigquery.DateFieldType:
+		return typeOf(typeOfDate), nil
+	case bigquery.TimeFieldType:
+		return typeOf(typeOfTime), nil
+	case bigquery.DateTimeFieldType:
+		return typeOf(typeOfDateTime), nil
+	case bigquery.TimestampFieldType:
+		return typeOf(typeOfGoTime), nil
+	case bigquery.NumericFieldType:
+		return typeOf(typeOfRat), nil
+
+	// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/bigquery/v1.85.0/bigquery/value.go#L436-L448
+	case bigquery.BigNumericFieldType:
+		return typeOf(typeOfRat), nil
+	case bigquery.IntervalFieldType:
+		// NOTE(ginokent): *bigquery.IntervalValue is the value of INTERVAL in the bigquery.Value rows, but the struct loader of bigquery.RowIterator
+		//                 has no setter for INTERVAL, so the field is documented by fieldTypeComment. ref. https://github.com/googleapis/google-cloud-go/blob/bigquery/v1.85.0/bigquery/value.go#L299-L449
+		return typeOf(typeOfIntervalValue), nil
+	case bigquery.RangeFieldType:
+		return typeOf(typeOfRangeValue), nil
+	case bigquery.JSONFieldType:
+		if g.opts.JSONType == JSONTypeRawMessage {
+			// NOTE(ginokent): json.RawMessage may be an alias (e.g. of jsontext.Value), so reflect can not be used for its name.
+			return GoType{Name: string(JSONTypeRawMessage), ImportPath: "encoding/json"}, nil
+		}
+		return GoType{Name: reflect.String.String()}, nil
+
+	// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L362-L364
+	case bigquery.IntegerFieldType:
+		return GoType{Name: reflect.Int64.String()}, nil
+
+	// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L368-L371
+	case bigquery.RecordFieldType:
+		// NOTE(ginokent): RECORD is generated as a nested struct by generateStructs, because its Go type name depends on the parent struct.
+		return GoType{}, fmt.Errorf("bigquery.FieldType not supported. bigquery.FieldType=%s", bigqueryFieldType)
+
+	// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L394-L399
+	case bigquery.StringFieldType, bigquery.GeographyFieldType:
+		return GoType{Name: reflect.String.String()}, nil
+	case bigquery.BooleanFieldType:
+		return GoType{Name: reflect.Bool.String()}, nil
+	case bigquery.FloatFieldType:
+		return GoType{Name: reflect.Float64.String()}, nil
+
+	// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L400-L401
+	default:
+		return GoType{}, fmt.Errorf("bigquery.FieldType not supported. bigquery.FieldType=%s", bigqueryFieldType)
+	}
+}
+
+// NOTE(ginokent): Standard SQL type names that the API reports as their legacy names. ref. https://cloud.google.com/bigquery/docs/reference/standard-sql/data-types
+var bigqueryFieldTypeAliases = map[string]bigquery.FieldType{
+	"INT64":      bigquery.IntegerFieldType,
+	"FLOAT64":    bigquery.FloatFieldType,
+	"BOOL":       bigquery.BooleanFieldType,
+	"DECIMAL":    bigquery.NumericFieldType,
+	"BIGDECIMAL": bigquery.BigNumericFieldType,
+	"STRUCT":     bigquery.RecordFieldType,
+}
+
+// typeOf returns the GoType of t.
+// NOTE(ginokent): The *T (pointer type) does not return the package path. ref. https://github.com/golang/go/blob/f0ff6d4a67ec9a956aa655d487543da034cf576b/src/reflect/type.go#L83
+func typeOf(t reflect.Type) (goType GoType) {
+	elem := t
+	for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Slice {
+		elem = elem.Elem()
+	}
+	return GoType{Name: t.String(), ImportPath: elem.PkgPath()}
+}
+
+// NormalizeFieldType returns the bigquery.FieldType of the type name, accepting the Standard SQL type names such as INT64 case-insensitively.
+func NormalizeFieldType(name string) (bigqueryFieldType bigquery.FieldType) {
+	name = strings.ToUpper(name)
+	if bigqueryFieldType, ok := bigqueryFieldTypeAliases[name]; ok {
+		return bigqueryFieldType
+	}
+	return bigquery.FieldType(name)
+}
@@ -0,0 +1,21 @@
+package bqschemagen
+
+import (
+	"log"
+	"strings"
+)
+
+func capitalizeInitial(s string) (capitalized string) {
+	if len(s) == 0 {
+		return ""
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func infoln(logger *log.Logger, content string) {
+	logger.Println("INFO: " + content)
+}
+
+func warnln(logger *log.Logger, content string) {
+	logger.Println("WARN: " + content)
+}
@@ -6,20 +6,14 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"go/format"
-	"io/ioutil"
 	"log"
-	"math/big"
 	"os"
-	"reflect"
+	"path/filepath"
 	"strconv"
 	"strings"
-	"time"
 
 	"cloud.google.com/go/bigquery"
-	"cloud.google.com/go/civil"
-	"golang.org/x/tools/imports"
-	"google.golang.org/api/iterator"
+	"github.com/ginokent/bqschema-gen-go/bqschemagen"
 )
 
 const (
@@ -27,37 +21,165 @@ const (
 	optNameProjectID  = "project"
 	optNameDataset    = "dataset"
 	optNameOutputFile = "output"
+	optNamePackage    = "package"
+	optNameLayout     = "output-layout"
+	optNameTemplate   = "template"
 	optNameDebug      = "debug"
+	optNameStrict     = "strict"
+	optNameCheck      = "check"
+	// multiple dataset options
+	optNameTargets       = "targets"
+	optNamePackageLayout = "package-layout"
+	// table selection options
+	optNameInclude        = "include"
+	optNameExclude        = "exclude"
+	optNameCollapseShards = "collapse-shards"
+	// metadata fetching options
+	optNameConcurrency       = "concurrency"
+	optNameMaxAttempts       = "max-attempts"
+	optNameRequestsPerSecond = "requests-per-second"
+	// offline input options
+	optNameSchemaDir      = "schema-dir"
+	optNameSchemaManifest = "schema-manifest"
+	optNameDDL            = "ddl"
 	// custom mapping options
+	optNameNaming           = "naming"
+	optNameInitialisms      = "initialisms"
+	optNameCollision        = "collision"
+	optNameTypeMap          = "type-map"
+	optNameColumnType       = "column-type"
+	optNameColumnName       = "column-name"
+	optNameTag              = "tag"
 	optNameTimestampType    = "timestamp-type"
 	optNameTimestampImports = "timestamp-imports"
+	optNameNullableMode     = "nullable-mode"
+	optNameJSONType         = "json-type"
 	// envName
-	envNameGCloudProjectID  = "GCLOUD_PROJECT_ID"
-	envNameBigQueryDataset  = "BIGQUERY_DATASET"
-	envNameOutputFile       = "OUTPUT_FILE"
-	envNameDebug            = "DEBUG"
-	envNameTimestampType    = "TIMESTAMP_TYPE"
-	envNameTimestampImports = "TIMESTAMP_IMPORTS"
+	envNameGCloudProjectID   = "GCLOUD_PROJECT_ID"
+	envNameBigQueryDataset   = "BIGQUERY_DATASET"
+	envNameOutputFile        = "OUTPUT_FILE"
+	envNamePackage           = "PACKAGE_NAME"
+	envNameLayout            = "OUTPUT_LAYOUT"
+	envNameTemplate          = "TEMPLATE_FILE"
+	envNameDebug             = "DEBUG"
+	envNameStrict            = "STRICT"
+	envNameCheck             = "CHECK"
+	envNameTargets           = "BIGQUERY_TARGETS"
+	envNamePackageLayout     = "PACKAGE_LAYOUT"
+	envNameInclude           = "INCLUDE_TABLES"
+	envNameExclude           = "EXCLUDE_TABLES"
+	envNameCollapseShards    = "COLLAPSE_SHARDS"
+	envNameConcurrency       = "CONCURRENCY"
+	envNameMaxAttempts       = "MAX_ATTEMPTS"
+	envNameRequestsPerSecond = "REQUESTS_PER_SECOND"
+	envNameSchemaDir         = "SCHEMA_DIR"
+	envNameSchemaManifest    = "SCHEMA_MANIFEST"
+	envNameDDL               = "DDL_FILES"
+	envNameNaming            = "NAMING"
+	envNameInitialisms       = "INITIALISMS"
+	envNameCollision         = "COLLISION"
+	envNameTypeMap           = "TYPE_MAP"
+	envNameColumnType        = "COLUMN_TYPE"
+	envNameColumnName        = "COLUMN_NAME"
+	envNameTags              = "TAGS"
+	envNameTimestampType     = "TIMESTAMP_TYPE"
+	envNameTimestampImports  = "TIMESTAMP_IMPORTS"
+	envNameNullableMode      = "NULLABLE_MODE"
+	envNameJSONType          = "JSON_TYPE"
 	// defaultValue
-	defaultValueEmpty      = ""
-	defaultValueOutputFile = "bqschema.generated.go"
-	defaultValueDebug      = "false"
+	defaultValueEmpty       = ""
+	defaultValueOutputFile  = "bqschema.generated.go"
+	defaultValueDebug       = "false"
+	defaultValueStrict      = "false"
+	defaultValueCheck       = "false"
+	defaultValueConcurrency = 8
+	defaultValueMaxAttempts = 5
+	// output layouts
+	outputLayoutFile  = "file"
+	outputLayoutTable = "table"
+	// package layouts of -targets
+	packageLayoutSingle  = "single"
+	packageLayoutDataset = "dataset"
 )
 
 var (
 	// optValue
-	optValueProjectID        = flag.String(optNameProjectID, defaultValueEmpty, "")
-	optValueDataset          = flag.String(optNameDataset, defaultValueEmpty, "")
-	optValueOutputPath       = flag.String(optNameOutputFile, defaultValueEmpty, "path to output the generated code")
-	optValueTimestampType    = flag.String(optNameTimestampType, defaultValueEmpty, "override Go type for BigQuery TIMESTAMP (e.g. 'time.Time' or 'mypkg.T')")
-	optValueTimestampImports = flag.String(optNameTimestampImports, defaultValueEmpty, "comma-separated import paths to add when overriding TIMESTAMP (e.g. 'time' or 'github.com/org/mypkg')")
+	optValueProjectID         = flag.String(optNameProjectID, defaultValueEmpty, "")
+	optValueDataset           = flag.String(optNameDataset, defaultValueEmpty, "")
+	optValueOutputPath        = flag.String(optNameOutputFile, defaultValueEmpty, "path to output the generated code")
+	optValuePackage           = flag.String(optNamePackage, defaultValueEmpty, "package name of the generated code (default: the name of the directory of -"+optNameOutputFile+", or of the dataset with -"+optNamePackageLayout+"="+packageLayoutDataset+")")
+	optValueLayout            = flag.String(optNameLayout, defaultValueEmpty, "output layout: '"+outputLayoutFile+"' (all tables into -"+optNameOutputFile+", default) or '"+outputLayoutTable+"' (a file <table>"+bqschemagen.GeneratedFileSuffix+" per table in the directory of -"+optNameOutputFile+", removing the generated files of the deleted tables)")
+	optValueTemplate          = flag.String(optNameTemplate, defaultValueEmpty, "path to the text/template file that redefines the default templates \"file\", \"table\", \"structs\", \"struct\" or \"field\" by {{define}}, e.g. to add methods or tags")
+	optValueStrict            = boolFlagVar(optNameStrict, "fail if any table can not be read or generated, reporting all of them, instead of skipping them with a warning (default: false)")
+	optValueCheck             = boolFlagVar(optNameCheck, "do not write the generated code, but print the unified diff from the existing output files and fail if they differ, e.g. for CI (default: false)")
+	optValueTargets           = stringsFlagVar(optNameTargets, "generate for the project.dataset targets instead of -"+optNameProjectID+" and -"+optNameDataset+", repeatable (e.g. 'proj-a.sales,proj-b.events'). -"+optNameProjectID+" is the project billed for the API requests if set")
+	optValuePackageLayout     = flag.String(optNamePackageLayout, defaultValueEmpty, "package layout of -"+optNameTargets+": '"+packageLayoutSingle+"' (all datasets into -"+optNameOutputFile+" with the struct names prefixed by the dataset, default) or '"+packageLayoutDataset+"' (one package per dataset in the directory of the dataset next to -"+optNameOutputFile+")")
+	optValueInclude           = stringsFlagVar(optNameInclude, "generate only the tables whose IDs match the glob pattern, or the regular expression prefixed with '"+bqschemagen.RegexpPatternPrefix+"', repeatable (e.g. 'events_*' or '"+bqschemagen.RegexpPatternPrefix+"^(users|orders)$'). the patterns can not contain commas")
+	optValueExclude           = stringsFlagVar(optNameExclude, "skip the tables whose IDs match the pattern like -"+optNameInclude+", even if -"+optNameInclude+" matches them, repeatable (e.g. 'tmp_*')")
+	optValueCollapseShards    = flag.String(optNameCollapseShards, defaultValueEmpty, "date-sharded tables (e.g. events_20240101): '"+string(bqschemagen.ShardModeOff)+"' (a struct per shard, default), '"+string(bqschemagen.ShardModeMerge)+"' (a struct per prefix from the latest shard, merging the compatible columns of the older shards with a warning) or '"+string(bqschemagen.ShardModeStrict)+"' (like '"+string(bqschemagen.ShardModeMerge)+"', but fail if the shard schemas diverge)")
+	optValueConcurrency       = flag.String(optNameConcurrency, defaultValueEmpty, "number of the table schemas read at the same time (default: "+strconv.Itoa(defaultValueConcurrency)+")")
+	optValueMaxAttempts       = flag.String(optNameMaxAttempts, defaultValueEmpty, "number of the attempts of a BigQuery API request that fails by 5xx, 429 or rateLimitExceeded, including the first one (default: "+strconv.Itoa(defaultValueMaxAttempts)+"). the generation fails if all attempts fail")
+	optValueRequestsPerSecond = flag.String(optNameRequestsPerSecond, defaultValueEmpty, "limit of the BigQuery API requests per second (default: unlimited)")
+	optValueSchemaDir         = flag.String(optNameSchemaDir, defaultValueEmpty, "generate from the table schema JSON files (bq show --schema --format=prettyjson) in the directory instead of the BigQuery API. table IDs are the file names without .json")
+	optValueDDL               = stringsFlagVar(optNameDDL, "generate from the CREATE TABLE, ALTER TABLE and DROP TABLE statements in the DDL files that match the glob pattern, applied in file name order, instead of the BigQuery API, repeatable (e.g. 'migrations/*.sql')")
+	optValueSchemaManifest    = flag.String(optNameSchemaManifest, defaultValueEmpty, "generate from the table schema JSON files listed in the manifest JSON file ({\"table_id\": \"path/to/schema.json\"}) instead of the BigQuery API")
+	optValueNaming            = flag.String(optNameNaming, defaultValueEmpty, "Go identifier naming: '"+string(bqschemagen.NamingModeCamel)+"' (user_id -> UserID, default) or '"+string(bqschemagen.NamingModeLegacy)+"' (user_id -> User_id)")
+	optValueInitialisms       = flag.String(optNameInitialisms, defaultValueEmpty, "comma-separated initialisms upper-cased by -"+optNameNaming+"="+string(bqschemagen.NamingModeCamel)+" (default: "+strings.Join(bqschemagen.DefaultInitialisms, ",")+")")
+	optValueCollision         = flag.String(optNameCollision, defaultValueEmpty, "how to resolve Go identifier and struct tag name collisions: '"+string(bqschemagen.CollisionSuffix)+"' (UserID, UserID_2, default) or '"+string(bqschemagen.CollisionError)+"' (fail)")
+	optValueTypeMap           = stringsFlagVar(optNameTypeMap, "override Go type for a BigQuery type as BIGQUERY_TYPE=import/path.Type, repeatable (e.g. 'NUMERIC=github.com/shopspring/decimal.Decimal')")
+	optValueColumnType        = stringsFlagVar(optNameColumnType, "override Go type for a column as table.column=import/path.Type, repeatable. The type is used as is, nested RECORD fields are table.record.column (e.g. 'orders.amount=github.com/org/money.Cents'). With -"+optNameTargets+", dataset.table.column or project:dataset.table.column overrides the column of a dataset only")
+	optValueColumnName        = stringsFlagVar(optNameColumnName, "override Go field name for a column as table.column=GoName, repeatable. nested RECORD fields are table.record.column (e.g. 'stories.time_ts=CreatedAt'). With -"+optNameTargets+", dataset.table.column or project:dataset.table.column overrides the column of a dataset only")
+	optValueTag               = stringsFlagVar(optNameTag, "add the struct tag after the bigquery tag as key[:naming][=template], repeatable. naming is '"+string(bqschemagen.TagNamingOriginal)+"' (default), '"+string(bqschemagen.TagNamingSnake)+"' or '"+string(bqschemagen.TagNamingCamel)+"', and template is the text/template of the value executed with .Name, .Schema and .Nullable (e.g. 'json:camel,db:snake,avro,parquet' adds json:\"userID,omitempty\" for a NULLABLE column user_id)")
+	optValueTimestampType     = flag.String(optNameTimestampType, defaultValueEmpty, "deprecated: use -"+optNameTypeMap+" TIMESTAMP=import/path.Type")
+	optValueTimestampImports  = flag.String(optNameTimestampImports, defaultValueEmpty, "deprecated: use -"+optNameTypeMap+" TIMESTAMP=import/path.Type")
+	optValueNullableMode      = flag.String(optNameNullableMode, defaultValueEmpty, "Go type for NULLABLE columns: '"+string(bqschemagen.NullableModeValue)+"' (plain values, default), '"+string(bqschemagen.NullableModeNull)+"' (bigquery.NullInt64 etc.) or '"+string(bqschemagen.NullableModePointer)+"' (*int64 etc., bigquery.RowIterator can not load it)")
+	optValueJSONType          = flag.String(optNameJSONType, defaultValueEmpty, "Go type for BigQuery JSON: '"+string(bqschemagen.JSONTypeString)+"' (default) or '"+string(bqschemagen.JSONTypeRawMessage)+"' (bigquery.RowIterator can not load it)")
 )
 
-// Global overrides configured via CLI/env
-var (
-	overrideTimestampType    string
-	overrideTimestampImports []string
-)
+// stringsFlag is a flag.Value that accumulates the values of a repeatable option.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func stringsFlagVar(name, usage string) *stringsFlag {
+	f := new(stringsFlag)
+	flag.Var(f, name, usage)
+	return f
+}
+
+// boolFlag is a flag.Value of a boolean option that can be set without the value (e.g. `-check` for `-check=true`).
+// It keeps the value as a string, which is empty unless the option is set, for getOptOrEnvOrDefault.
+type boolFlag string
+
+func (f *boolFlag) String() string {
+	return string(*f)
+}
+
+func (f *boolFlag) Set(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return fmt.Errorf("must be true or false: %s", value)
+	}
+	*f = boolFlag(value)
+	return nil
+}
+
+// IsBoolFlag makes flag.Parse accept the option without the value.
+func (f *boolFlag) IsBoolFlag() bool {
+	return true
+}
+
+func boolFlagVar(name, usage string) *boolFlag {
+	f := new(boolFlag)
+	flag.Var(f, name, usage)
+	return f
+}
 
 func main() {
 
@@ -74,17 +196,77 @@ func main() {
 func Run(ctx context.Context) (err error) {
 	flag.Parse()
 
+	var schemaDir string
+	schemaDir, err = getOptOrEnvOrDefault(optNameSchemaDir, *optValueSchemaDir, envNameSchemaDir, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
+
+	var schemaManifest string
+	schemaManifest, err = getOptOrEnvOrDefault(optNameSchemaManifest, *optValueSchemaManifest, envNameSchemaManifest, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
+
+	var ddlCSV string
+	ddlCSV, err = getOptOrEnvOrDefault(optNameDDL, optValueDDL.String(), envNameDDL, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
+
+	inputs := 0
+	for _, input := range []string{schemaDir, schemaManifest, ddlCSV} {
+		if input != "" {
+			inputs++
+		}
+	}
+	if inputs > 1 {
+		return fmt.Errorf("set only one of -%s, -%s or -%s", optNameSchemaDir, optNameSchemaManifest, optNameDDL)
+	}
+	// NOTE(ginokent): project and dataset are only used for the comments when generating from the local files.
+	offline := inputs > 0
+
+	var targetsCSV string
+	targetsCSV, err = getOptOrEnvOrDefault(optNameTargets, optValueTargets.String(), envNameTargets, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
+
+	var targets []target
+	targets, err = parseTargets(targetsCSV)
+	if err != nil {
+		return fmt.Errorf("parseTargets: %w", err)
+	}
+	if len(targets) > 0 && offline {
+		return fmt.Errorf("-%s can not be set with -%s, -%s or -%s", optNameTargets, optNameSchemaDir, optNameSchemaManifest, optNameDDL)
+	}
+
+	var packageLayout string
+	packageLayout, err = getOptOrEnvOrDefault(optNamePackageLayout, *optValuePackageLayout, envNamePackageLayout, packageLayoutSingle, false)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
+	switch packageLayout {
+	case packageLayoutSingle, packageLayoutDataset:
+	default:
+		return fmt.Errorf("invalid -%s: %s", optNamePackageLayout, packageLayout)
+	}
+
+	// NOTE(ginokent): with -targets, project is only the project billed for the API requests, and defaults to the project of the first target.
 	var project string
-	project, err = getOptOrEnvOrDefault(optNameProjectID, *optValueProjectID, envNameGCloudProjectID, "", false)
+	project, err = getOptOrEnvOrDefault(optNameProjectID, *optValueProjectID, envNameGCloudProjectID, "", offline || len(targets) > 0)
 	if err != nil {
 		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
 
 	var dataset string
-	dataset, err = getOptOrEnvOrDefault(optNameDataset, *optValueDataset, envNameBigQueryDataset, "", false)
+	dataset, err = getOptOrEnvOrDefault(optNameDataset, *optValueDataset, envNameBigQueryDataset, "", offline || len(targets) > 0)
 	if err != nil {
 		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
+	if dataset != "" && len(targets) > 0 {
+		return fmt.Errorf("set only one of -%s or -%s", optNameDataset, optNameTargets)
+	}
 
 	var filePath string
 	filePath, err = getOptOrEnvOrDefault(optNameOutputFile, *optValueOutputPath, envNameOutputFile, defaultValueOutputFile, false)
@@ -92,6 +274,39 @@ func Run(ctx context.Context) (err error) {
 		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
 
+	var packageName string
+	packageName, err = getOptOrEnvOrDefault(optNamePackage, *optValuePackage, envNamePackage, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
+
+	var templatePath string
+	templatePath, err = getOptOrEnvOrDefault(optNameTemplate, *optValueTemplate, envNameTemplate, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
+	var templateText []byte
+	if templatePath != "" {
+		templateText, err = os.ReadFile(templatePath)
+		if err != nil {
+			return fmt.Errorf("os.ReadFile: %w", err)
+		}
+	}
+
+	var outputLayout string
+	outputLayout, err = getOptOrEnvOrDefault(optNameLayout, *optValueLayout, envNameLayout, outputLayoutFile, false)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
+	switch outputLayout {
+	case outputLayoutFile, outputLayoutTable:
+	default:
+		return fmt.Errorf("invalid -%s: %s", optNameLayout, outputLayout)
+	}
+	if outputLayout == outputLayoutTable && len(targets) > 0 && packageLayout == packageLayoutSingle {
+		return fmt.Errorf("-%s=%s can not be set with -%s=%s", optNameLayout, outputLayoutTable, optNamePackageLayout, packageLayoutSingle)
+	}
+
 	var debugString string
 	debugString, err = getOptOrEnvOrDefault(optNameDebug, *optValueOutputPath, envNameDebug, defaultValueDebug, false)
 	if err != nil {
@@ -99,231 +314,445 @@ func Run(ctx context.Context) (err error) {
 	}
 	debug, _ := strconv.ParseBool(debugString)
 
-	var timestampTypeOverride string
-	timestampTypeOverride, err = getOptOrEnvOrDefault(optNameTimestampType, *optValueTimestampType, envNameTimestampType, defaultValueEmpty, true)
+	var strictString string
+	strictString, err = getOptOrEnvOrDefault(optNameStrict, optValueStrict.String(), envNameStrict, defaultValueStrict, false)
 	if err != nil {
 		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
+	var strict bool
+	strict, err = strconv.ParseBool(strictString)
+	if err != nil {
+		return fmt.Errorf("-%s must be true or false: %s", optNameStrict, strictString)
+	}
 
-	var timestampImportsCSV string
-	timestampImportsCSV, err = getOptOrEnvOrDefault(optNameTimestampImports, *optValueTimestampImports, envNameTimestampImports, defaultValueEmpty, true)
+	var checkString string
+	checkString, err = getOptOrEnvOrDefault(optNameCheck, optValueCheck.String(), envNameCheck, defaultValueCheck, false)
 	if err != nil {
 		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
+	var check bool
+	check, err = strconv.ParseBool(checkString)
+	if err != nil {
+		return fmt.Errorf("-%s must be true or false: %s", optNameCheck, checkString)
+	}
 
-	// set global overrides for use during generation
-	overrideTimestampType = strings.TrimSpace(timestampTypeOverride)
-	overrideTimestampImports = nil
-	if strings.TrimSpace(timestampImportsCSV) != "" {
-		for _, p := range strings.Split(timestampImportsCSV, ",") {
-			p = strings.TrimSpace(p)
-			if p != "" {
-				overrideTimestampImports = append(overrideTimestampImports, p)
-			}
-		}
+	var namingModeString string
+	namingModeString, err = getOptOrEnvOrDefault(optNameNaming, *optValueNaming, envNameNaming, string(bqschemagen.NamingModeCamel), false)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
 
-	client, err := bigquery.NewClient(ctx, project)
+	var initialismsCSV string
+	initialismsCSV, err = getOptOrEnvOrDefault(optNameInitialisms, *optValueInitialisms, envNameInitialisms, strings.Join(bqschemagen.DefaultInitialisms, ","), false)
 	if err != nil {
-		return fmt.Errorf("bigquery.NewClient: %w", err)
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
-	defer func() {
-		if closeErr := client.Close(); closeErr != nil {
-			warnln("client.Close: " + closeErr.Error())
-		}
-	}()
 
-	generatedCode, err := Generate(ctx, client, dataset, debug)
+	var collisionString string
+	collisionString, err = getOptOrEnvOrDefault(optNameCollision, *optValueCollision, envNameCollision, string(bqschemagen.CollisionSuffix), false)
 	if err != nil {
-		return fmt.Errorf("Generate: %w", err)
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
 
-	// NOTE(ginokent): output
-	if err = ioutil.WriteFile(filePath, generatedCode, 0644); err != nil {
-		return fmt.Errorf("ioutil.WriteFile: %w", err)
+	var typeMapCSV string
+	typeMapCSV, err = getOptOrEnvOrDefault(optNameTypeMap, optValueTypeMap.String(), envNameTypeMap, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
 
-	return nil
-}
+	var typeMap map[bigquery.FieldType]bqschemagen.GoType
+	typeMap, err = bqschemagen.ParseTypeMap(typeMapCSV)
+	if err != nil {
+		return fmt.Errorf("bqschemagen.ParseTypeMap: %w", err)
+	}
 
-func Generate(ctx context.Context, client *bigquery.Client, dataset string, debug bool) (generatedCode []byte, err error) {
+	var columnTypeCSV string
+	columnTypeCSV, err = getOptOrEnvOrDefault(optNameColumnType, optValueColumnType.String(), envNameColumnType, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
 
-	const head = `// Code generated by go run github.com/ginokent/bqschema-gen-go; DO NOT EDIT.
+	var columnTypes map[string]bqschemagen.GoType
+	columnTypes, err = bqschemagen.ParseColumnTypeMap(columnTypeCSV)
+	if err != nil {
+		return fmt.Errorf("bqschemagen.ParseColumnTypeMap: %w", err)
+	}
 
-//go:generate go run github.com/ginokent/bqschema-gen-go
+	var columnNameCSV string
+	columnNameCSV, err = getOptOrEnvOrDefault(optNameColumnName, optValueColumnName.String(), envNameColumnName, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
 
-package bqschema
+	var columnNames map[string]string
+	columnNames, err = bqschemagen.ParseColumnNameMap(columnNameCSV)
+	if err != nil {
+		return fmt.Errorf("bqschemagen.ParseColumnNameMap: %w", err)
+	}
 
-`
+	var tagsCSV string
+	tagsCSV, err = getOptOrEnvOrDefault(optNameTag, optValueTag.String(), envNameTags, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
 
-	tables, err := getAllTables(ctx, client, dataset)
+	var tags []bqschemagen.TagOption
+	tags, err = bqschemagen.ParseTagOptions(tagsCSV)
 	if err != nil {
-		return nil, fmt.Errorf("getAllTables: %w", err)
+		return fmt.Errorf("bqschemagen.ParseTagOptions: %w", err)
 	}
 
-	var tail string
-	var importPackages []string
-	for _, table := range tables {
-		var structCode string
-		var pkgs []string
-		structCode, pkgs, err = generateTableSchemaCode(ctx, table)
-		if err != nil {
-			warnln("generateTableSchemaCode: " + err.Error())
-			continue
-		}
+	var includeCSV string
+	includeCSV, err = getOptOrEnvOrDefault(optNameInclude, optValueInclude.String(), envNameInclude, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
 
-		if len(pkgs) > 0 {
-			importPackages = append(importPackages, pkgs...)
-		}
-		tail = tail + structCode
+	var excludeCSV string
+	excludeCSV, err = getOptOrEnvOrDefault(optNameExclude, optValueExclude.String(), envNameExclude, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
 
-	// append user-specified imports for TIMESTAMP override (if any)
-	if overrideTimestampType != "" && len(overrideTimestampImports) > 0 {
-		importPackages = append(importPackages, overrideTimestampImports...)
+	var collapseShardsString string
+	collapseShardsString, err = getOptOrEnvOrDefault(optNameCollapseShards, *optValueCollapseShards, envNameCollapseShards, string(bqschemagen.ShardModeOff), false)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
 
-	importCode := generateImportPackagesCode(importPackages)
+	var concurrencyString string
+	concurrencyString, err = getOptOrEnvOrDefault(optNameConcurrency, *optValueConcurrency, envNameConcurrency, strconv.Itoa(defaultValueConcurrency), false)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
+	var concurrency int
+	concurrency, err = strconv.Atoi(concurrencyString)
+	if err != nil || concurrency < 1 {
+		return fmt.Errorf("-%s must be a positive integer: %s", optNameConcurrency, concurrencyString)
+	}
 
-	// NOTE(ginokent): combine
-	code := head + importCode + tail
+	var maxAttemptsString string
+	maxAttemptsString, err = getOptOrEnvOrDefault(optNameMaxAttempts, *optValueMaxAttempts, envNameMaxAttempts, strconv.Itoa(defaultValueMaxAttempts), false)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
+	var maxAttempts int
+	maxAttempts, err = strconv.Atoi(maxAttemptsString)
+	if err != nil || maxAttempts < 1 {
+		return fmt.Errorf("-%s must be a positive integer: %s", optNameMaxAttempts, maxAttemptsString)
+	}
 
-	if debug {
-		fmt.Println(">>>> DEBUG >>>>>>>>>>>>>>>>")
-		fmt.Println(code)
-		fmt.Println("<<<< DEBUG <<<<<<<<<<<<<<<<")
+	var requestsPerSecondString string
+	requestsPerSecondString, err = getOptOrEnvOrDefault(optNameRequestsPerSecond, *optValueRequestsPerSecond, envNameRequestsPerSecond, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
+	var requestsPerSecond float64
+	if requestsPerSecondString != "" {
+		requestsPerSecond, err = strconv.ParseFloat(requestsPerSecondString, 64)
+		if err != nil || requestsPerSecond <= 0 {
+			return fmt.Errorf("-%s must be a positive number: %s", optNameRequestsPerSecond, requestsPerSecondString)
+		}
 	}
 
-	gen := []byte(code)
+	var timestampTypeOverride string
+	timestampTypeOverride, err = getOptOrEnvOrDefault(optNameTimestampType, *optValueTimestampType, envNameTimestampType, defaultValueEmpty, true)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
+	}
 
-	genFmt, err := format.Source(gen)
+	var timestampImportsCSV string
+	timestampImportsCSV, err = getOptOrEnvOrDefault(optNameTimestampImports, *optValueTimestampImports, envNameTimestampImports, defaultValueEmpty, true)
 	if err != nil {
-		return nil, fmt.Errorf("format.Source: %w", err)
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
 
-	if debug {
-		fmt.Println(">>>> DEBUG >>>>>>>>>>>>>>>>")
-		fmt.Println(string(genFmt))
-		fmt.Println("<<<< DEBUG <<<<<<<<<<<<<<<<")
+	var nullableModeString string
+	nullableModeString, err = getOptOrEnvOrDefault(optNameNullableMode, *optValueNullableMode, envNameNullableMode, string(bqschemagen.NullableModeValue), false)
+	if err != nil {
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
 
-	genImports, err := imports.Process("", genFmt, nil)
+	var jsonTypeString string
+	jsonTypeString, err = getOptOrEnvOrDefault(optNameJSONType, *optValueJSONType, envNameJSONType, string(bqschemagen.JSONTypeString), false)
 	if err != nil {
-		return nil, fmt.Errorf("imports.Process: %w", err)
+		return fmt.Errorf("getOptOrEnvOrDefault: %w", err)
 	}
 
-	return genImports, nil
-}
+	if timestampTypeOverride != "" {
+		warnln("-" + optNameTimestampType + " and -" + optNameTimestampImports + " are deprecated. use -" + optNameTypeMap + " TIMESTAMP=import/path.Type")
+		if _, ok := typeMap[bigquery.TimestampFieldType]; ok {
+			return fmt.Errorf("TIMESTAMP is overridden by both -%s and -%s", optNameTypeMap, optNameTimestampType)
+		}
+		var goType bqschemagen.GoType
+		goType, err = parseDeprecatedTimestampOverride(timestampTypeOverride, timestampImportsCSV)
+		if err != nil {
+			return fmt.Errorf("parseDeprecatedTimestampOverride: %w", err)
+		}
+		typeMap[bigquery.TimestampFieldType] = goType
+	}
+
+	opts := bqschemagen.Options{
+		NamingMode:        bqschemagen.NamingMode(namingModeString),
+		Initialisms:       strings.Split(initialismsCSV, ","),
+		Collision:         bqschemagen.CollisionStrategy(collisionString),
+		TypeMap:           typeMap,
+		ColumnTypes:       columnTypes,
+		ColumnNames:       columnNames,
+		Tags:              tags,
+		NullableMode:      bqschemagen.NullableMode(nullableModeString),
+		JSONType:          bqschemagen.JSONType(jsonTypeString),
+		Include:           splitPatterns(includeCSV),
+		Exclude:           splitPatterns(excludeCSV),
+		CollapseShards:    bqschemagen.ShardMode(collapseShardsString),
+		Concurrency:       concurrency,
+		MaxAttempts:       maxAttempts,
+		RequestsPerSecond: requestsPerSecond,
+		Strict:            strict,
+		PackageName:       packageName,
+		Template:          string(templateText),
+		Debug:             debug,
+	}
+
+	out := newOutput(check)
+
+	if len(targets) > 0 {
+		if err = generateTargets(ctx, opts, project, targets, packageLayout, outputLayout, filePath, out); err != nil {
+			return fmt.Errorf("generateTargets: %w", err)
+		}
+		return out.err()
+	}
 
-func generateImportPackagesCode(importPackages []string) (generatedCode string) {
-	importPackagesUniq := make(map[string]bool)
-	for _, pkg := range importPackages {
-		importPackagesUniq[pkg] = true
+	if opts.PackageName == "" {
+		opts.PackageName = outputPackageName(filePath)
 	}
 
-	// NOTE(ginokent): fix order
-	importPackagesUniqSort := make([]string, len(importPackagesUniq))
-	idx := 0
-	for _, pkg := range importPackages {
-		if importPackagesUniq[pkg] {
-			importPackagesUniq[pkg] = false
-			importPackagesUniqSort[idx] = pkg
-			idx++
-		}
+	var generator *bqschemagen.Generator
+	generator, err = bqschemagen.New(opts)
+	if err != nil {
+		return fmt.Errorf("bqschemagen.New: %w", err)
 	}
 
+	var source bqschemagen.SchemaSource
 	switch {
-	case len(importPackagesUniq) == 0:
-		generatedCode = ""
-	case len(importPackagesUniq) == 1:
-		for pkg := range importPackagesUniq {
-			generatedCode = "import \"" + pkg + "\"\n"
+	case ddlCSV != "":
+		source, err = bqschemagen.NewDDLSource(strings.Split(ddlCSV, ","), project, dataset, nil)
+		if err != nil {
+			return fmt.Errorf("bqschemagen.NewDDLSource: %w", err)
+		}
+	case schemaDir != "":
+		source, err = bqschemagen.NewSchemaDirSource(schemaDir, project, dataset)
+		if err != nil {
+			return fmt.Errorf("bqschemagen.NewSchemaDirSource: %w", err)
+		}
+	case schemaManifest != "":
+		source, err = bqschemagen.NewSchemaManifestSource(schemaManifest, project, dataset)
+		if err != nil {
+			return fmt.Errorf("bqschemagen.NewSchemaManifestSource: %w", err)
 		}
-		generatedCode = generatedCode + "\n"
-	case len(importPackagesUniq) >= 2:
-		generatedCode = "import (\n"
-		for _, pkg := range importPackagesUniqSort {
-			generatedCode = generatedCode + "\t\"" + pkg + "\"\n"
+	default:
+		var client *bigquery.Client
+		client, err = bigquery.NewClient(ctx, project)
+		if err != nil {
+			return fmt.Errorf("bigquery.NewClient: %w", err)
+		}
+		defer func() {
+			if closeErr := client.Close(); closeErr != nil {
+				warnln("client.Close: " + closeErr.Error())
+			}
+		}()
+		source = bqschemagen.NewBigQuerySource(client, dataset)
+	}
+
+	if outputLayout == outputLayoutTable {
+		var files map[string][]byte
+		files, err = generator.GenerateFiles(ctx, source)
+		if err != nil {
+			return fmt.Errorf("generator.GenerateFiles: %w", err)
 		}
-		generatedCode = generatedCode + ")\n\n"
+
+		if err = out.writeFiles(filepath.Dir(filePath), files); err != nil {
+			return fmt.Errorf("out.writeFiles: %w", err)
+		}
+		return out.err()
+	}
+
+	generatedCode, err := generator.Generate(ctx, source)
+	if err != nil {
+		return fmt.Errorf("generator.Generate: %w", err)
+	}
+
+	// NOTE(ginokent): output
+	if err = out.writeFile(filePath, generatedCode); err != nil {
+		return fmt.Errorf("out.writeFile: %w", err)
 	}
 
-	return generatedCode
+	return out.err()
 }
 
-func generateTableSchemaCode(ctx context.Context, table *bigquery.Table) (generatedCode string, importPackages []string, err error) {
-	tableID := table.TableID
-	if len(tableID) == 0 {
-		return "", nil, fmt.Errorf("*bigquery.Table.TableID is empty. *bigquery.Table struct dump: %#v", table)
+// outputPackageName returns the default package name for filePath, which is the name of the directory of filePath.
+func outputPackageName(filePath string) (packageName string) {
+	dir, err := filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		dir = filepath.Dir(filePath)
 	}
+	return sanitizePackageName(filepath.Base(dir))
+}
+
+// target is a BigQuery dataset of -targets.
+type target struct {
+	project string
+	dataset string
+}
+
+// parseTargets parses comma-separated project.dataset targets.
+// The project is split at the last dot, because the domain-scoped project IDs such as `example.com:proj` have dots.
+func parseTargets(targetsCSV string) (targets []target, err error) {
+	uniq := make(map[target]bool)
+	for _, entry := range strings.Split(targetsCSV, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
 
-	if strings.Contains(tableID, "-") {
-		replaced := strings.ReplaceAll(tableID, "-", "_")
-		warnln(fmt.Sprintf("tableID `%s` contains invalid character `-`. replacing `%s` to `%s`", tableID, tableID, replaced))
-		tableID = replaced
+		dot := strings.LastIndex(entry, ".")
+		if dot <= 0 || dot == len(entry)-1 {
+			return nil, fmt.Errorf("invalid target, expected project.dataset: %s", entry)
+		}
+
+		t := target{project: entry[:dot], dataset: entry[dot+1:]}
+		if uniq[t] {
+			return nil, fmt.Errorf("duplicate target: %s", entry)
+		}
+		uniq[t] = true
+		targets = append(targets, t)
 	}
+	return targets, nil
+}
 
-	structName := capitalizeInitial(tableID)
+// targetPackageNames returns the package names of targets for -package-layout=dataset.
+// The package name is the dataset ID, or the project ID and the dataset ID if the same dataset ID is given for more than one project.
+func targetPackageNames(targets []target) (packageNames []string) {
+	projects := make(map[string]int)
+	for _, t := range targets {
+		projects[t.dataset]++
+	}
 
-	var md *bigquery.TableMetadata
-	md, err = table.Metadata(ctx)
+	packageNames = make([]string, len(targets))
+	for i, t := range targets {
+		name := t.dataset
+		if projects[t.dataset] > 1 {
+			name = t.project + "_" + t.dataset
+		}
+		packageNames[i] = sanitizePackageName(name)
+	}
+	return packageNames
+}
+
+// sanitizePackageName converts name into a lower-case Go package name.
+func sanitizePackageName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(name))
+	if name == "" || name[0] >= '0' && name[0] <= '9' || name == "_" {
+		name = "x" + name
+	}
+	return name
+}
+
+// generateTargets generates the code for targets from the BigQuery API into filePath through out,
+// or into the package of each dataset next to filePath if packageLayout is packageLayoutDataset, in outputLayout.
+func generateTargets(ctx context.Context, opts bqschemagen.Options, project string, targets []target, packageLayout, outputLayout, filePath string, out *output) (err error) {
+	if project == "" {
+		project = targets[0].project
+	}
+
+	client, err := bigquery.NewClient(ctx, project)
 	if err != nil {
-		return "", nil, fmt.Errorf("table.Metadata: %w", err)
+		return fmt.Errorf("bigquery.NewClient: %w", err)
 	}
+	defer func() {
+		if closeErr := client.Close(); closeErr != nil {
+			warnln("client.Close: " + closeErr.Error())
+		}
+	}()
 
-	// NOTE(ginokent): structs
-	generatedCode = "// " + structName + " is BigQuery Table `" + md.FullID + "` schema struct.\n" +
-		"// Description: " + md.Description + "\n" +
-		"type " + structName + " struct {\n"
+	datasets := make([]bqschemagen.Dataset, len(targets))
+	for i, t := range targets {
+		datasets[i] = bqschemagen.Dataset{ProjectID: t.project, DatasetID: t.dataset, Source: bqschemagen.NewBigQueryDatasetSource(client, t.project, t.dataset)}
+	}
 
-	schemas := []*bigquery.FieldSchema(md.Schema)
+	if packageLayout == packageLayoutSingle && opts.PackageName == "" {
+		opts.PackageName = outputPackageName(filePath)
+	}
+	generator, err := bqschemagen.New(opts)
+	if err != nil {
+		return fmt.Errorf("bqschemagen.New: %w", err)
+	}
 
-	for _, schema := range schemas {
-		var goTypeStr, pkg string
-		goTypeStr, pkg, err = bigqueryFieldTypeToGoType(schema.Type)
+	if packageLayout == packageLayoutSingle {
+		var generatedCode []byte
+		generatedCode, err = generator.GenerateDatasets(ctx, datasets)
 		if err != nil {
-			return "", nil, fmt.Errorf("bigqueryFieldTypeToGoType: tableID=%s, %w", tableID, err)
+			return fmt.Errorf("generator.GenerateDatasets: %w", err)
 		}
-		if pkg != "" {
-			importPackages = append(importPackages, pkg)
+
+		if err = out.writeFile(filePath, generatedCode); err != nil {
+			return fmt.Errorf("out.writeFile: %w", err)
 		}
-		generatedCode = generatedCode + "\t" + capitalizeInitial(schema.Name) + " " + goTypeStr + " `bigquery:\"" + schema.Name + "\"`\n"
+		return nil
 	}
-	generatedCode = generatedCode + "}\n"
 
-	return generatedCode, importPackages, nil
-}
+	// NOTE(ginokent): the package of each dataset is in the directory of the package name next to filePath, even if -package overrides the package name.
+	dirNames := targetPackageNames(targets)
+	packageNames := make([]string, len(dirNames))
+	for i, dirName := range dirNames {
+		packageNames[i] = dirName
+		if opts.PackageName != "" {
+			packageNames[i] = opts.PackageName
+		}
+	}
 
-func getAllTables(ctx context.Context, client *bigquery.Client, datasetID string) (tables []*bigquery.Table, err error) {
-	tableIterator := client.Dataset(datasetID).Tables(ctx)
-	for {
-		var table *bigquery.Table
-		table, err = tableIterator.Next()
+	if outputLayout == outputLayoutTable {
+		var packageFiles []map[string][]byte
+		packageFiles, err = generator.GeneratePackageFiles(ctx, datasets, packageNames)
 		if err != nil {
-			if err == iterator.Done {
-				break
+			return fmt.Errorf("generator.GeneratePackageFiles: %w", err)
+		}
+
+		for i, files := range packageFiles {
+			if err = out.writeFiles(filepath.Join(filepath.Dir(filePath), dirNames[i]), files); err != nil {
+				return fmt.Errorf("out.writeFiles: %w", err)
 			}
-			return nil, fmt.Errorf("tableIterator.Next: %w", err)
 		}
-		tables = append(tables, table)
+		return nil
 	}
-	return tables, nil
-}
 
-func readFile(path string) (content []byte, err error) {
-	var file *os.File
-	file, err = os.Open(path)
+	generatedCodes, err := generator.GeneratePackages(ctx, datasets, packageNames)
 	if err != nil {
-		return nil, fmt.Errorf("os.Open: %w", err)
+		return fmt.Errorf("generator.GeneratePackages: %w", err)
 	}
 
-	var bytea []byte
-	bytea, err = ioutil.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("ioutil.ReadAll: %w", err)
+	for i, generatedCode := range generatedCodes {
+		if err = out.writeFile(filepath.Join(filepath.Dir(filePath), dirNames[i], filepath.Base(filePath)), generatedCode); err != nil {
+			return fmt.Errorf("out.writeFile: %w", err)
+		}
 	}
 
-	return bytea, nil
+	return nil
+}
+
+// splitPatterns splits comma-separated table ID patterns, dropping the empty ones.
+func splitPatterns(patternsCSV string) (patterns []string) {
+	for _, pattern := range strings.Split(patternsCSV, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
 }
 
 func getOptOrEnvOrDefault(optName, optValue, envName, defaultValue string, allowEmptyValue bool) (value string, err error) {
@@ -354,13 +783,6 @@ func getOptOrEnvOrDefault(optName, optValue, envName, defaultValue string, allow
 	return "", fmt.Errorf("set option -%s, or set environment variable %s", optName, envName)
 }
 
-func capitalizeInitial(s string) (capitalized string) {
-	if len(s) == 0 {
-		return ""
-	}
-	return strings.ToUpper(s[:1]) + s[1:]
-}
-
 func infoln(content string) {
 	log.Println("INFO: " + content)
 }
@@ -380,61 +802,22 @@ func exit(code int) {
 	os.Exit(code)
 }
 
-// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L216
-var typeOfByteSlice = reflect.TypeOf([]byte{})
-
-// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/params.go#L81-L87
-var (
-	typeOfDate     = reflect.TypeOf(civil.Date{})
-	typeOfTime     = reflect.TypeOf(civil.Time{})
-	typeOfDateTime = reflect.TypeOf(civil.DateTime{})
-	typeOfGoTime   = reflect.TypeOf(time.Time{})
-	typeOfRat      = reflect.TypeOf(&big.Rat{})
-)
-
-func bigqueryFieldTypeToGoType(bigqueryFieldType bigquery.FieldType) (goType string, pkg string, err error) {
-	switch bigqueryFieldType {
-	// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L342-L343
-	case bigquery.BytesFieldType:
-		return typeOfByteSlice.String(), "", nil
-
-	// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L344-L358
-	case bigquery.DateFieldType:
-		return typeOfDate.String(), typeOfDate.PkgPath(), nil
-	case bigquery.TimeFieldType:
-		return typeOfTime.String(), typeOfTime.PkgPath(), nil
-	case bigquery.DateTimeFieldType:
-		return typeOfDateTime.String(), typeOfDateTime.PkgPath(), nil
-	case bigquery.TimestampFieldType:
-		if overrideTimestampType != "" {
-			// When overriding, we don't automatically return a package path; imports are handled separately.
-			return overrideTimestampType, "", nil
+// parseDeprecatedTimestampOverride converts -timestamp-type and -timestamp-imports into the Go type of TIMESTAMP.
+func parseDeprecatedTimestampOverride(timestampType, timestampImportsCSV string) (goType bqschemagen.GoType, err error) {
+	var timestampImports []string
+	for _, p := range strings.Split(timestampImportsCSV, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			timestampImports = append(timestampImports, p)
 		}
-		return typeOfGoTime.String(), typeOfGoTime.PkgPath(), nil
-	case bigquery.NumericFieldType:
-		// NOTE(ginokent): The *T (pointer type) does not return the package path.
-		//               ref. https://github.com/golang/go/blob/f0ff6d4a67ec9a956aa655d487543da034cf576b/src/reflect/type.go#L83
-		return typeOfRat.String(), reflect.TypeOf(big.Rat{}).PkgPath(), nil
-
-	// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L362-L364
-	case bigquery.IntegerFieldType:
-		return reflect.Int64.String(), "", nil
-
-	// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L368-L371
-	case bigquery.RecordFieldType:
-		// TODO(ginokent): support bigquery.RecordFieldType
-		return "", "", fmt.Errorf("bigquery.FieldType not supported. bigquery.FieldType=%s", bigqueryFieldType)
-
-	// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L394-L399
-	case bigquery.StringFieldType, bigquery.GeographyFieldType:
-		return reflect.String.String(), "", nil
-	case bigquery.BooleanFieldType:
-		return reflect.Bool.String(), "", nil
-	case bigquery.FloatFieldType:
-		return reflect.Float64.String(), "", nil
-
-	// NOTE(ginokent): ref. https://github.com/googleapis/google-cloud-go/blob/f37f118c87d4d0a77a554515a430ae06e5852294/bigquery/schema.go#L400-L401
+	}
+
+	switch len(timestampImports) {
+	case 0:
+		return bqschemagen.GoType{Name: strings.TrimSpace(timestampType)}, nil
+	case 1:
+		return bqschemagen.GoType{Name: strings.TrimSpace(timestampType), ImportPath: timestampImports[0]}, nil
 	default:
-		return "", "", fmt.Errorf("bigquery.FieldType not supported. bigquery.FieldType=%s", bigqueryFieldType)
+		return bqschemagen.GoType{}, fmt.Errorf("multiple imports are not supported, use -%s TIMESTAMP=import/path.Type: %s", optNameTypeMap, timestampImportsCSV)
 	}
 }
@@ -2,13 +2,16 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 
-	"cloud.google.com/go/bigquery"
-	"google.golang.org/api/iterator"
+	"github.com/ginokent/bqschema-gen-go/bqschemagen"
 )
 
 const (
@@ -16,21 +19,12 @@ const (
 	testEmptyString                = ""
 	GOOGLE_APPLICATION_CREDENTIALS = "GOOGLE_APPLICATION_CREDENTIALS"
 
-	// generateTableSchemaCode, getAllTables
-	testPublicDataProjectID         = "bigquery-public-data"
-	testSupportedDatasetID          = "hacker_news"
-	testNotSupportedDatasetID       = "samples"
-	testProjectNotFound             = "projectnotfound"
-	testDatasetNotFound             = "datasetnotfound"
-	testSubStrFieldTypeNotSupported = "bigquery.FieldType not supported."
-
-	// getAllTables
-	testGoogleApplicationCredentials = "test/serviceaccountnotfound@projectnotfound.iam.gserviceaccount.com.json"
-
-	// readFile
-	testErrNoSuchFileOrDirectoryPath = "/no/such/file/or/directory"
-	testErrIsADirectoryPath          = "."
-	testProbablyExistsPath           = "go.mod"
+	// Run
+	testPublicDataProjectID = "bigquery-public-data"
+	testSupportedDatasetID  = "hacker_news"
+	testSchemaDir           = "test/schema"
+	testSchemaManifest      = "test/schema.manifest.json"
+	testDDLPattern          = "test/ddl/*.sql"
 
 	// getOptOrEnvOrDefault
 	testOptName      = "test-opt-key"
@@ -38,13 +32,6 @@ const (
 	testEnvName      = "TEST_ENV_KEY"
 	testEnvValue     = "testEnvValue"
 	testDefaultValue = "testDefaultValue"
-
-	// capitalizeInitial
-	testNotCapitalized = "a"
-	testCapitalized    = "A"
-
-	// bigqueryFieldTypeToGoType
-	testNotSupportedFieldType = "notSupportedFieldType"
 )
 
 func Test_Run(t *testing.T) {
@@ -84,255 +71,369 @@ func Test_Run(t *testing.T) {
 	})
 }
 
-func Test_Generate(t *testing.T) {
-	t.Run("正常系_testSupportedDatasetID_"+testSupportedDatasetID, func(t *testing.T) {
-		if os.Getenv(GOOGLE_APPLICATION_CREDENTIALS) == "" {
-			t.Skip("WARN: " + GOOGLE_APPLICATION_CREDENTIALS + " is not set")
+func Test_Run_offline(t *testing.T) {
+	t.Run("正常系_testSchemaDir", func(t *testing.T) {
+		outputFile := filepath.Join(t.TempDir(), defaultValueOutputFile)
+		t.Setenv(envNameSchemaDir, testSchemaDir)
+		t.Setenv(envNameOutputFile, outputFile)
+
+		if err := Run(context.Background()); err != nil {
+			t.Error(err)
 		}
+		if _, err := os.ReadFile(outputFile); err != nil {
+			t.Error(err)
+		}
+	})
 
-		var (
-			ctx       = context.Background()
-			client, _ = bigquery.NewClient(ctx, testPublicDataProjectID)
-		)
+	t.Run("正常系_testDDLPattern", func(t *testing.T) {
+		outputFile := filepath.Join(t.TempDir(), defaultValueOutputFile)
+		t.Setenv(envNameDDL, testDDLPattern)
+		t.Setenv(envNameOutputFile, outputFile)
 
-		_, err := Generate(ctx, client, testSupportedDatasetID)
+		if err := Run(context.Background()); err != nil {
+			t.Error(err)
+		}
+		code, err := os.ReadFile(outputFile)
 		if err != nil {
 			t.Error(err)
 		}
-	})
-
-	t.Run("正常系_testNotSupportedDatasetID_"+testNotSupportedDatasetID, func(t *testing.T) {
-		if os.Getenv(GOOGLE_APPLICATION_CREDENTIALS) == "" {
-			t.Skip("WARN: " + GOOGLE_APPLICATION_CREDENTIALS + " is not set")
+		if !strings.Contains(string(code), "type Users struct") || !strings.Contains(string(code), "type Events struct") {
+			t.Errorf("Run: code=%s", code)
 		}
+	})
 
-		var (
-			ctx       = context.Background()
-			client, _ = bigquery.NewClient(ctx, testPublicDataProjectID)
-		)
+	t.Run("正常系_testDDLPattern_exclude", func(t *testing.T) {
+		outputFile := filepath.Join(t.TempDir(), defaultValueOutputFile)
+		t.Setenv(envNameDDL, testDDLPattern)
+		t.Setenv(envNameExclude, "re:^event")
+		t.Setenv(envNameOutputFile, outputFile)
 
-		_, err := Generate(ctx, client, testNotSupportedDatasetID)
+		if err := Run(context.Background()); err != nil {
+			t.Error(err)
+		}
+		code, err := os.ReadFile(outputFile)
 		if err != nil {
 			t.Error(err)
 		}
+		if !strings.Contains(string(code), "type Users struct") || strings.Contains(string(code), "type Events struct") {
+			t.Errorf("Run: code=%s", code)
+		}
 	})
-}
 
-func Test_generateImportPackagesCode(t *testing.T) {
-	t.Run("正常系_import_nothing", func(t *testing.T) {
-		const (
-			// 正しい出力
-			testImportCode = ""
-		)
-		var (
-			testImportsSlice = []string{}
-		)
+	t.Run("異常系_strict", func(t *testing.T) {
+		schemaDir := t.TempDir()
+		for name, content := range map[string]string{
+			"ok.json": `[{"name": "id", "type": "INTEGER"}]`,
+			"ng.json": `{`,
+		} {
+			if err := os.WriteFile(filepath.Join(schemaDir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		t.Setenv(envNameSchemaDir, schemaDir)
+		t.Setenv(envNameOutputFile, filepath.Join(t.TempDir(), defaultValueOutputFile))
+
+		// NOTE(ginokent): a table that can not be read by an error other than 4xx of the BigQuery API is never skipped.
+		if err := Run(context.Background()); err == nil || !strings.Contains(err.Error(), "readSchemaFile: ") {
+			t.Error(err)
+		}
 
-		generatedCode := generateImportPackagesCode(testImportsSlice)
-		if generatedCode != testImportCode {
-			t.Error()
+		t.Setenv(envNameStrict, "true")
+		err := Run(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "ng: readTable: ") {
+			t.Error(err)
 		}
 	})
 
-	t.Run("正常系_import_time", func(t *testing.T) {
-		const (
-			// 正しい出力
-			testImportCode = "import \"time\"\n\n"
-		)
-		var (
-			testImportsSlice = []string{"time"}
-		)
-		generatedCode := generateImportPackagesCode(testImportsSlice)
+	t.Run("異常系_check", func(t *testing.T) {
+		outputFile := filepath.Join(t.TempDir(), defaultValueOutputFile)
+		t.Setenv(envNameDDL, testDDLPattern)
+		t.Setenv(envNameOutputFile, outputFile)
+		if err := Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
 
-		if generatedCode != testImportCode {
-			var (
-				rr      = strings.NewReplacer("\n", "\\n", "`", "\\`")
-				want    = rr.Replace(testImportCode)
-				current = rr.Replace(generatedCode)
-			)
-			t.Error("generateImportPackagesCode: want=`" + want + "` current=`" + current + "`")
+		t.Setenv(envNameCheck, "true")
+		if err := Run(context.Background()); err != nil {
+			t.Error(err)
+		}
+
+		// NOTE(ginokent): the schema drift that the committed code is not regenerated for.
+		t.Setenv(envNameExclude, "re:^event")
+		if err := Run(context.Background()); !errors.Is(err, errOutOfDate) {
+			t.Error(err)
+		}
+		code, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Error(err)
+		}
+		if !strings.Contains(string(code), "type Events struct") {
+			t.Errorf("Run: the output file is written by -%s: code=%s", optNameCheck, code)
 		}
 	})
 
-	t.Run("正常系_import_math/big_time", func(t *testing.T) {
-		const (
-			// 正しい出力
-			testImportCode = `import (
-	"math/big"
-	"time"
-)
+	t.Run("正常系_output_layout_table", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "warehouse")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for fileName, content := range map[string]string{
+			"deleted.generated.go": bqschemagen.GeneratedCodeComment + "\n\npackage warehouse\n",
+			"manual.generated.go":  "package warehouse\n",
+		} {
+			if err := os.WriteFile(filepath.Join(dir, fileName), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		t.Setenv(envNameDDL, testDDLPattern)
+		t.Setenv(envNameLayout, outputLayoutTable)
+		t.Setenv(envNameOutputFile, filepath.Join(dir, defaultValueOutputFile))
 
-`
-		)
-		var (
-			testImportsSlice = []string{"math/big", "time"}
-		)
-		generatedCode := generateImportPackagesCode(testImportsSlice)
+		if err := Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		code, err := os.ReadFile(filepath.Join(dir, "users.generated.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(code), "\npackage warehouse\n") || !strings.Contains(string(code), "type Users struct") || strings.Contains(string(code), "type Events struct") {
+			t.Errorf("Run: code=%s", code)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "deleted.generated.go")); !os.IsNotExist(err) {
+			t.Errorf("Run: the stale generated file is not removed: err=%v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "manual.generated.go")); err != nil {
+			t.Errorf("Run: the file not generated by bqschema-gen-go is removed: err=%v", err)
+		}
 
-		if generatedCode != testImportCode {
-			var (
-				rr      = strings.NewReplacer("\n", "\\n", "`", "\\`")
-				want    = rr.Replace(testImportCode)
-				current = rr.Replace(generatedCode)
-			)
-			t.Error("generateImportPackagesCode: want=`" + want + "` current=`" + current + "`")
+		t.Setenv(envNameCheck, "true")
+		if err := Run(context.Background()); err != nil {
+			t.Error(err)
 		}
 	})
-}
 
-func Test_generateTableSchemaCode(t *testing.T) {
-	t.Run("正常系_testPublicDataProjectID_testPublicDataProjectID", func(t *testing.T) {
-		var (
-			ctx = context.Background()
-		)
+	t.Run("正常系_package", func(t *testing.T) {
+		outputFile := filepath.Join(t.TempDir(), defaultValueOutputFile)
+		t.Setenv(envNameDDL, testDDLPattern)
+		t.Setenv(envNamePackage, "warehouse")
+		t.Setenv(envNameOutputFile, outputFile)
 
-		if os.Getenv(GOOGLE_APPLICATION_CREDENTIALS) == "" {
-			t.Skip("WARN: " + GOOGLE_APPLICATION_CREDENTIALS + " is not set")
+		if err := Run(context.Background()); err != nil {
+			t.Error(err)
+		}
+		code, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Error(err)
+		}
+		if !strings.Contains(string(code), "\npackage warehouse\n") {
+			t.Errorf("Run: code=%s", code)
 		}
+	})
 
-		var (
-			ngClient, _     = bigquery.NewClient(ctx, testPublicDataProjectID)
-			ngTableIterator = ngClient.Dataset(testSupportedDatasetID).Tables(ctx)
-		)
+	t.Run("正常系_template", func(t *testing.T) {
+		templateFile := filepath.Join(t.TempDir(), "bqschema.tmpl")
+		if err := os.WriteFile(templateFile, []byte(`{{define "table"}}{{template "structs" .Structs}}
+func ({{.StructName}}) TableName() string { return {{quote .Table.TableID}} }
+{{end}}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		outputFile := filepath.Join(t.TempDir(), defaultValueOutputFile)
+		t.Setenv(envNameDDL, testDDLPattern)
+		t.Setenv(envNameTemplate, templateFile)
+		t.Setenv(envNameOutputFile, outputFile)
 
-		for {
-			table, err := ngTableIterator.Next()
-			if err == iterator.Done {
-				break
-			}
-			if err != nil {
-				t.Error(err)
-			}
-			if _, _, err := generateTableSchemaCode(ctx, table); err != nil {
-				t.Error(err)
-			}
+		if err := Run(context.Background()); err != nil {
+			t.Error(err)
+		}
+		code, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Error(err)
+		}
+		if !strings.Contains(string(code), `func (Users) TableName() string { return "users" }`) {
+			t.Errorf("Run: code=%s", code)
 		}
 	})
 
-	t.Run("異常系_testProjectNotFound_testDatasetNotFound", func(t *testing.T) {
-		var (
-			ctx     = context.Background()
-			ngTable = &bigquery.Table{
-				ProjectID: testProjectNotFound,
-				DatasetID: testDatasetNotFound,
-				TableID:   testEmptyString,
-			}
-		)
-		if _, _, err := generateTableSchemaCode(ctx, ngTable); err == nil {
+	t.Run("正常系_tag", func(t *testing.T) {
+		outputFile := filepath.Join(t.TempDir(), defaultValueOutputFile)
+		t.Setenv(envNameDDL, testDDLPattern)
+		t.Setenv(envNameTags, "json:camel,db:snake")
+		t.Setenv(envNameOutputFile, outputFile)
+
+		if err := Run(context.Background()); err != nil {
+			t.Error(err)
+		}
+		code, err := os.ReadFile(outputFile)
+		if err != nil {
 			t.Error(err)
 		}
+		for _, want := range []string{
+			"`bigquery:\"user_id\" json:\"userID\" db:\"user_id\"`",
+			"`bigquery:\"name\" json:\"name,omitempty\" db:\"name\"`",
+		} {
+			if !strings.Contains(string(code), want) {
+				t.Errorf("Run: want=%s code=%s", want, code)
+			}
+		}
 	})
 
-	t.Run("異常系_testProjectNotFound_testNotSupportedDatasetID", func(t *testing.T) {
-		var (
-			ctx = context.Background()
-		)
+	t.Run("異常系_tag", func(t *testing.T) {
+		t.Setenv(envNameDDL, testDDLPattern)
+		t.Setenv(envNameTags, "json:kebab")
+		t.Setenv(envNameOutputFile, filepath.Join(t.TempDir(), defaultValueOutputFile))
 
-		if os.Getenv(GOOGLE_APPLICATION_CREDENTIALS) == "" {
-			t.Skip("WARN: " + GOOGLE_APPLICATION_CREDENTIALS + " is not set")
+		if err := Run(context.Background()); err == nil {
+			t.Error(err)
 		}
+	})
 
-		var (
-			ngClient, _ = bigquery.NewClient(ctx, testPublicDataProjectID)
-			ngTable, _  = ngClient.Dataset(testNotSupportedDatasetID).Tables(ctx).Next()
-		)
+	t.Run("異常系_template_not_found", func(t *testing.T) {
+		t.Setenv(envNameDDL, testDDLPattern)
+		t.Setenv(envNameTemplate, filepath.Join(t.TempDir(), "notfound.tmpl"))
+		t.Setenv(envNameOutputFile, filepath.Join(t.TempDir(), defaultValueOutputFile))
 
-		ngTable.ProjectID = testProjectNotFound
-		if _, _, err := generateTableSchemaCode(ctx, ngTable); err == nil {
+		if err := Run(context.Background()); err == nil {
 			t.Error(err)
 		}
 	})
 
-	t.Run("異常系_testPublicDataProjectID_testNotSupportedDatasetID_testSubStrFieldTypeNotSupported", func(t *testing.T) {
-		var (
-			ctx = context.Background()
-		)
+	t.Run("異常系_output_layout", func(t *testing.T) {
+		t.Setenv(envNameDDL, testDDLPattern)
+		t.Setenv(envNameLayout, "dataset")
+		t.Setenv(envNameOutputFile, filepath.Join(t.TempDir(), defaultValueOutputFile))
 
-		if os.Getenv(GOOGLE_APPLICATION_CREDENTIALS) == "" {
-			t.Skip("WARN: " + GOOGLE_APPLICATION_CREDENTIALS + " is not set")
+		if err := Run(context.Background()); err == nil {
+			t.Error(err)
 		}
+	})
 
-		var (
-			ngClient, _     = bigquery.NewClient(ctx, testPublicDataProjectID)
-			ngTableIterator = ngClient.Dataset(testNotSupportedDatasetID).Tables(ctx)
-		)
+	t.Run("異常系_both_testSchemaDir_testDDLPattern", func(t *testing.T) {
+		t.Setenv(envNameSchemaDir, testSchemaDir)
+		t.Setenv(envNameDDL, testDDLPattern)
+		t.Setenv(envNameOutputFile, filepath.Join(t.TempDir(), defaultValueOutputFile))
 
-		for {
-			table, err := ngTableIterator.Next()
-			if err == iterator.Done {
-				break
-			}
-			if err != nil {
-				t.Error(err)
-			}
-			if _, _, err := generateTableSchemaCode(ctx, table); err != nil {
-				// NOTE(ginokent): "bigquery.FieldType not supported." 以外のエラーが出たら Fail
-				if !strings.Contains(err.Error(), testSubStrFieldTypeNotSupported) {
-					t.Error(err)
-				}
-				// NOTE(ginokent): ここまで来たら、確認したいことは確認済み。
-				// ref. https://github.com/ginokent/bqschema-gen-go/blob/260524ce0ae2dd5bdcbdd57446cdd8c140326ca4/main.go#L212
-				return
-			}
+		if err := Run(context.Background()); err == nil {
+			t.Error(err)
 		}
 	})
-}
 
-func Test_getAllTables(t *testing.T) {
-	t.Run("正常系_testPublicDataProjectID_testSupportedDatasetID", func(t *testing.T) {
+	t.Run("異常系_both_testSchemaDir_testSchemaManifest", func(t *testing.T) {
+		t.Setenv(envNameSchemaDir, testSchemaDir)
+		t.Setenv(envNameSchemaManifest, testSchemaManifest)
+		t.Setenv(envNameOutputFile, filepath.Join(t.TempDir(), defaultValueOutputFile))
 
-		if os.Getenv(GOOGLE_APPLICATION_CREDENTIALS) == "" {
-			t.Skip("WARN: " + GOOGLE_APPLICATION_CREDENTIALS + " is not set")
+		if err := Run(context.Background()); err == nil {
+			t.Error(err)
 		}
+	})
+}
 
-		var (
-			ctx         = context.Background()
-			okClient, _ = bigquery.NewClient(ctx, testPublicDataProjectID)
-		)
+func Test_Run_targets(t *testing.T) {
+	t.Run("異常系_targets_testSchemaDir", func(t *testing.T) {
+		t.Setenv(envNameTargets, "proj-a.sales")
+		t.Setenv(envNameSchemaDir, testSchemaDir)
+		t.Setenv(envNameOutputFile, filepath.Join(t.TempDir(), defaultValueOutputFile))
 
-		if _, err := getAllTables(ctx, okClient, testSupportedDatasetID); err != nil {
+		if err := Run(context.Background()); err == nil {
 			t.Error(err)
 		}
 	})
 
-	t.Run("異常系_testProjectNotFound_testDatasetNotFound", func(t *testing.T) {
+	t.Run("異常系_targets_dataset", func(t *testing.T) {
+		t.Setenv(envNameTargets, "proj-a.sales")
+		t.Setenv(envNameBigQueryDataset, testSupportedDatasetID)
+		t.Setenv(envNameOutputFile, filepath.Join(t.TempDir(), defaultValueOutputFile))
 
-		backupValue, exist := os.LookupEnv(GOOGLE_APPLICATION_CREDENTIALS)
-		_ = os.Setenv(GOOGLE_APPLICATION_CREDENTIALS, testGoogleApplicationCredentials)
-		defer func() {
-			if exist {
-				_ = os.Setenv(GOOGLE_APPLICATION_CREDENTIALS, backupValue)
-				return
-			}
-			_ = os.Unsetenv(GOOGLE_APPLICATION_CREDENTIALS)
-		}()
+		if err := Run(context.Background()); err == nil {
+			t.Error(err)
+		}
+	})
 
-		var (
-			ctx         = context.Background()
-			ngClient, _ = bigquery.NewClient(ctx, testProjectNotFound)
-		)
+	t.Run("異常系_package_layout", func(t *testing.T) {
+		t.Setenv(envNameTargets, "proj-a.sales")
+		t.Setenv(envNamePackageLayout, "project")
+		t.Setenv(envNameOutputFile, filepath.Join(t.TempDir(), defaultValueOutputFile))
 
-		if _, err := getAllTables(ctx, ngClient, testDatasetNotFound); err == nil {
+		if err := Run(context.Background()); err == nil {
 			t.Error(err)
 		}
 	})
 }
 
-func Test_readFile(t *testing.T) {
-	t.Run("正常系_testProbablyExistsPath", func(t *testing.T) {
-		if _, err := readFile(testProbablyExistsPath); err != nil {
-			t.Error(err)
+func Test_parseTargets(t *testing.T) {
+	t.Run("正常系", func(t *testing.T) {
+		targets, err := parseTargets("proj-a.sales, example.com:proj-b.events,")
+		if err != nil {
+			t.Fatal(err)
 		}
+		want := []target{{project: "proj-a", dataset: "sales"}, {project: "example.com:proj-b", dataset: "events"}}
+		if !reflect.DeepEqual(targets, want) {
+			t.Errorf("parseTargets: want=%v current=%v", want, targets)
+		}
+	})
+
+	for _, targetsCSV := range []string{"sales", ".sales", "proj-a.", "proj-a.sales,proj-a.sales"} {
+		targetsCSV := targetsCSV
+		t.Run("異常系_"+targetsCSV, func(t *testing.T) {
+			if _, err := parseTargets(targetsCSV); err == nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_targetPackageNames(t *testing.T) {
+	packageNames := targetPackageNames([]target{
+		{project: "proj-a", dataset: "sales"},
+		{project: "proj-b", dataset: "sales"},
+		{project: "proj-b", dataset: "Events"},
+		{project: "proj-b", dataset: "2020_raw"},
 	})
+	want := []string{"proj_a_sales", "proj_b_sales", "events", "x2020_raw"}
+	if !reflect.DeepEqual(packageNames, want) {
+		t.Errorf("targetPackageNames: want=%v current=%v", want, packageNames)
+	}
+}
+
+func Test_outputPackageName(t *testing.T) {
+	for filePath, want := range map[string]string{
+		"internal/warehouse/bqschema.generated.go": "warehouse",
+		"bqschema-gen-go/bqschema.generated.go":    "bqschema_gen_go",
+		"/2024/bqschema.generated.go":              "x2024",
+	} {
+		if packageName := outputPackageName(filePath); packageName != want {
+			t.Error("outputPackageName: want=`" + want + "` current=`" + packageName + "`")
+		}
+	}
+}
 
-	t.Run("異常系_testErrNoSuchFileOrDirectoryPath", func(t *testing.T) {
-		if _, err := readFile(testErrNoSuchFileOrDirectoryPath); err == nil {
+func Test_boolFlag(t *testing.T) {
+	for args, want := range map[string]string{
+		"":              "",
+		"-check":        "true",
+		"-check=true":   "true",
+		"-check=false":  "false",
+		"-check=0":      "0",
+		"-check -x=1":   "true",
+		"-x=1 -check=1": "1",
+	} {
+		flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+		check := new(boolFlag)
+		flagSet.Var(check, optNameCheck, "")
+		flagSet.String("x", "", "")
+		if err := flagSet.Parse(strings.Fields(args)); err != nil {
 			t.Error(err)
 		}
-	})
+		if check.String() != want {
+			t.Error("boolFlag: args=" + args + " want=" + want + " current=" + check.String())
+		}
+	}
 
-	t.Run("異常系_testErrIsADirectoryPath", func(t *testing.T) {
-		if _, err := readFile(testErrIsADirectoryPath); err == nil {
+	t.Run("異常系", func(t *testing.T) {
+		flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+		flagSet.SetOutput(io.Discard)
+		flagSet.Var(new(boolFlag), optNameCheck, "")
+		if err := flagSet.Parse([]string{"-check=yes"}); err == nil {
 			t.Error(err)
 		}
 	})
@@ -340,7 +441,7 @@ func Test_readFile(t *testing.T) {
 
 func Test_getOptOrEnvOrDefault(t *testing.T) {
 	t.Run("正常系_testOptValue", func(t *testing.T) {
-		v, err := getOptOrEnvOrDefault(testOptName, testOptValue, testEnvName, testDefaultValue)
+		v, err := getOptOrEnvOrDefault(testOptName, testOptValue, testEnvName, testDefaultValue, false)
 		if err != nil {
 			t.Error(err)
 		}
@@ -353,7 +454,7 @@ func Test_getOptOrEnvOrDefault(t *testing.T) {
 		if err := os.Setenv(testEnvName, testEnvValue); err != nil {
 			t.Error(err)
 		}
-		v, err := getOptOrEnvOrDefault(testOptName, testEmptyString, testEnvName, testDefaultValue)
+		v, err := getOptOrEnvOrDefault(testOptName, testEmptyString, testEnvName, testDefaultValue, false)
 		if err != nil {
 			t.Error(err)
 		}
@@ -366,7 +467,7 @@ func Test_getOptOrEnvOrDefault(t *testing.T) {
 	})
 
 	t.Run("正常系_testDefaultValue", func(t *testing.T) {
-		v, err := getOptOrEnvOrDefault(testOptName, testEmptyString, testEnvName, testDefaultValue)
+		v, err := getOptOrEnvOrDefault(testOptName, testEmptyString, testEnvName, testDefaultValue, false)
 		if err != nil {
 			t.Error(err)
 		}
@@ -376,7 +477,7 @@ func Test_getOptOrEnvOrDefault(t *testing.T) {
 	})
 
 	t.Run("異常系_testEmptyString_all", func(t *testing.T) {
-		v, err := getOptOrEnvOrDefault(testEmptyString, testEmptyString, testEmptyString, testEmptyString)
+		v, err := getOptOrEnvOrDefault(testEmptyString, testEmptyString, testEmptyString, testEmptyString, false)
 		if err == nil {
 			t.Error(err)
 		}
@@ -386,7 +487,7 @@ func Test_getOptOrEnvOrDefault(t *testing.T) {
 	})
 
 	t.Run("異常系_testEmptyString", func(t *testing.T) {
-		v, err := getOptOrEnvOrDefault(testOptName, testEmptyString, testEnvName, testEmptyString)
+		v, err := getOptOrEnvOrDefault(testOptName, testEmptyString, testEnvName, testEmptyString, false)
 		if err == nil {
 			t.Error(err)
 		}
@@ -396,20 +497,6 @@ func Test_getOptOrEnvOrDefault(t *testing.T) {
 	})
 }
 
-func Test_capitalizeInitial(t *testing.T) {
-	t.Run("正常系_testEmptyString", func(t *testing.T) {
-		if capitalizeInitial(testEmptyString) != testEmptyString {
-			t.Error()
-		}
-	})
-
-	t.Run("正常系_testCapitalized", func(t *testing.T) {
-		if capitalizeInitial(testNotCapitalized) != testCapitalized {
-			t.Error()
-		}
-	})
-}
-
 func Test_infoln(t *testing.T) {
 	infoln("test")
 }
@@ -440,52 +527,3 @@ func Test_exit(t *testing.T) {
 
 	exit(1)
 }
-
-func Test_bigqueryFieldTypeToGoType(t *testing.T) {
-	var (
-		supportedBigqueryFieldTypes = map[bigquery.FieldType]string{
-			bigquery.StringFieldType:    reflect.String.String(),
-			bigquery.BytesFieldType:     typeOfByteSlice.String(),
-			bigquery.IntegerFieldType:   reflect.Int64.String(),
-			bigquery.FloatFieldType:     reflect.Float64.String(),
-			bigquery.BooleanFieldType:   reflect.Bool.String(),
-			bigquery.TimestampFieldType: typeOfGoTime.String(),
-			// TODO(ginokent): support bigquery.RecordFieldType
-			//bigquery.RecordFieldType: "",
-			bigquery.DateFieldType:      typeOfDate.String(),
-			bigquery.TimeFieldType:      typeOfTime.String(),
-			bigquery.DateTimeFieldType:  typeOfDateTime.String(),
-			bigquery.NumericFieldType:   typeOfRat.String(),
-			bigquery.GeographyFieldType: reflect.String.String(),
-		}
-
-		unsupportedBigqueryFieldTypes = map[bigquery.FieldType]string{
-			bigquery.RecordFieldType:                      testEmptyString,
-			bigquery.FieldType(testNotSupportedFieldType): testEmptyString,
-		}
-	)
-
-	t.Run("正常系_supportedBigqueryFieldTypes", func(t *testing.T) {
-		for bigqueryFieldType, typeOf := range supportedBigqueryFieldTypes {
-			goType, _, err := bigqueryFieldTypeToGoType(bigqueryFieldType)
-			if err != nil {
-				t.Error(err)
-			}
-			if goType != typeOf {
-				t.Error()
-			}
-		}
-	})
-
-	t.Run("異常系_unsupportedBigqueryFieldTypes", func(t *testing.T) {
-		for bigqueryFieldType, typeOf := range unsupportedBigqueryFieldTypes {
-			goType, _, err := bigqueryFieldTypeToGoType(bigqueryFieldType)
-			if err == nil {
-				t.Error(err)
-			}
-			if goType != typeOf {
-				t.Error()
-			}
-		}
-	})
-}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ginokent/bqschema-gen-go/bqschemagen"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// errOutOfDate is returned by -check when the generated code differs from the existing output files.
+var errOutOfDate = errors.New("generated code is out of date")
+
+// output writes the generated files, or with check, prints the unified diffs of the generated files and the existing ones without writing anything.
+type output struct {
+	check bool
+	// diffWriter is where the diffs of check are printed.
+	diffWriter io.Writer
+	// outOfDate is the files that differ from the generated code in check.
+	outOfDate []string
+}
+
+func newOutput(check bool) *output {
+	return &output{check: check, diffWriter: os.Stdout}
+}
+
+// writeFile writes generatedCode into filePath, creating the directory of it, or compares them with check.
+func (o *output) writeFile(filePath string, generatedCode []byte) (err error) {
+	if o.check {
+		return o.checkFile(filePath, generatedCode)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("os.MkdirAll: %w", err)
+	}
+	if err = os.WriteFile(filePath, generatedCode, 0644); err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+	return nil
+}
+
+// checkFile prints the unified diff of the existing filePath and generatedCode if they differ.
+// NOTE(ginokent): a file that does not exist is compared as an empty file, so that a new table is also reported.
+func (o *output) checkFile(filePath string, generatedCode []byte) (err error) {
+	existingCode, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("os.ReadFile: %w", err)
+	}
+	if bytes.Equal(existingCode, generatedCode) {
+		return nil
+	}
+
+	if err = o.printDiff(filePath, existingCode, filePath+" (generated)", generatedCode); err != nil {
+		return fmt.Errorf("printDiff: %w", err)
+	}
+	o.outOfDate = append(o.outOfDate, filePath)
+	return nil
+}
+
+// writeFiles writes the files of bqschemagen.Generator.GenerateFiles keyed by the file name into dir,
+// and removes the stale generated files in dir like removeStaleFiles.
+func (o *output) writeFiles(dir string, files map[string][]byte) (err error) {
+	fileNames := make([]string, 0, len(files))
+	for fileName := range files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	for _, fileName := range fileNames {
+		if err = o.writeFile(filepath.Join(dir, fileName), files[fileName]); err != nil {
+			return fmt.Errorf("writeFile: %w", err)
+		}
+	}
+	if err = o.removeStaleFiles(dir, fileNames); err != nil {
+		return fmt.Errorf("removeStaleFiles: %w", err)
+	}
+	return nil
+}
+
+// removeStaleFiles removes the files generated by bqschema-gen-go in dir that are not in fileNames, such as the files of the deleted tables,
+// or prints the diffs of removing them with check. The files that do not start with bqschemagen.GeneratedCodeComment are kept.
+func (o *output) removeStaleFiles(dir string, fileNames []string) (err error) {
+	keep := make(map[string]bool, len(fileNames))
+	for _, fileName := range fileNames {
+		keep[fileName] = true
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("os.ReadDir: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), bqschemagen.GeneratedFileSuffix) || keep[entry.Name()] {
+			continue
+		}
+		filePath := filepath.Join(dir, entry.Name())
+
+		var existingCode []byte
+		existingCode, err = os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("os.ReadFile: %w", err)
+		}
+		if !bytes.HasPrefix(existingCode, []byte(bqschemagen.GeneratedCodeComment+"\n")) {
+			continue
+		}
+
+		if o.check {
+			if err = o.printDiff(filePath, existingCode, "/dev/null", nil); err != nil {
+				return fmt.Errorf("printDiff: %w", err)
+			}
+			o.outOfDate = append(o.outOfDate, filePath)
+			continue
+		}
+		if err = os.Remove(filePath); err != nil {
+			return fmt.Errorf("os.Remove: %w", err)
+		}
+		infoln("remove the stale generated file: " + filePath)
+	}
+
+	return nil
+}
+
+// printDiff prints the unified diff of fromCode and toCode into diffWriter.
+func (o *output) printDiff(fromFile string, fromCode []byte, toFile string, toCode []byte) (err error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(fromCode),
+		B:        splitLines(toCode),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("difflib.GetUnifiedDiffString: %w", err)
+	}
+	if _, err = io.WriteString(o.diffWriter, diff); err != nil {
+		return fmt.Errorf("io.WriteString: %w", err)
+	}
+	return nil
+}
+
+// splitLines splits code into the lines of difflib.UnifiedDiff.
+// NOTE(ginokent): difflib.SplitLines returns an empty line for an empty string, which is printed as a removed or added line.
+func splitLines(code []byte) (lines []string) {
+	if len(code) == 0 {
+		return nil
+	}
+	return difflib.SplitLines(string(code))
+}
+
+// err returns errOutOfDate with the files that differ, after all files are written or compared.
+func (o *output) err() error {
+	if len(o.outOfDate) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", errOutOfDate, strings.Join(o.outOfDate, ", "))
+}
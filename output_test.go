@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ginokent/bqschema-gen-go/bqschemagen"
+)
+
+func Test_output(t *testing.T) {
+	const (
+		testCode    = "package bqschema\n\ntype Users struct {\n\tID int64 `bigquery:\"id\"`\n}\n"
+		testNewCode = "package bqschema\n\ntype Users struct {\n\tID   int64  `bigquery:\"id\"`\n\tName string `bigquery:\"name\"`\n}\n"
+	)
+
+	t.Run("正常系_writeFile", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "sales", defaultValueOutputFile)
+		out := newOutput(false)
+		if err := out.writeFile(filePath, []byte(testCode)); err != nil {
+			t.Fatal(err)
+		}
+		if code, err := os.ReadFile(filePath); err != nil || string(code) != testCode {
+			t.Errorf("writeFile: code=%s err=%v", code, err)
+		}
+		if err := out.err(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("正常系_check_up_to_date", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), defaultValueOutputFile)
+		if err := os.WriteFile(filePath, []byte(testCode), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var diff bytes.Buffer
+		out := newOutput(true)
+		out.diffWriter = &diff
+		if err := out.writeFile(filePath, []byte(testCode)); err != nil {
+			t.Fatal(err)
+		}
+		if err := out.err(); err != nil || diff.Len() != 0 {
+			t.Errorf("err: diff=%s err=%v", diff.String(), err)
+		}
+	})
+
+	t.Run("異常系_check_out_of_date", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), defaultValueOutputFile)
+		if err := os.WriteFile(filePath, []byte(testCode), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var diff bytes.Buffer
+		out := newOutput(true)
+		out.diffWriter = &diff
+		if err := out.writeFile(filePath, []byte(testNewCode)); err != nil {
+			t.Fatal(err)
+		}
+		if err := out.err(); !errors.Is(err, errOutOfDate) {
+			t.Error(err)
+		}
+		for _, want := range []string{
+			"--- " + filePath + "\n",
+			"+++ " + filePath + " (generated)\n",
+			"-\tID int64 `bigquery:\"id\"`\n",
+			"+\tName string `bigquery:\"name\"`\n",
+		} {
+			if !strings.Contains(diff.String(), want) {
+				t.Error("checkFile: want=`" + want + "` current=`" + diff.String() + "`")
+			}
+		}
+		if code, err := os.ReadFile(filePath); err != nil || string(code) != testCode {
+			t.Errorf("checkFile: the file is written: code=%s err=%v", code, err)
+		}
+	})
+
+	t.Run("異常系_check_not_exist", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "sales", defaultValueOutputFile)
+
+		var diff bytes.Buffer
+		out := newOutput(true)
+		out.diffWriter = &diff
+		if err := out.writeFile(filePath, []byte(testCode)); err != nil {
+			t.Fatal(err)
+		}
+		if err := out.err(); !errors.Is(err, errOutOfDate) {
+			t.Error(err)
+		}
+		if _, err := os.Stat(filepath.Dir(filePath)); !os.IsNotExist(err) {
+			t.Errorf("checkFile: the directory is created: err=%v", err)
+		}
+	})
+
+	t.Run("異常系_check_stale_file", func(t *testing.T) {
+		dir := t.TempDir()
+		staleFile := filepath.Join(dir, "deleted.generated.go")
+		if err := os.WriteFile(staleFile, []byte(bqschemagen.GeneratedCodeComment+"\n\npackage bqschema\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var diff bytes.Buffer
+		out := newOutput(true)
+		out.diffWriter = &diff
+		if err := out.writeFiles(dir, map[string][]byte{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := out.err(); !errors.Is(err, errOutOfDate) || !strings.Contains(err.Error(), staleFile) {
+			t.Error(err)
+		}
+		if want := "+++ /dev/null\n"; !strings.Contains(diff.String(), want) {
+			t.Error("removeStaleFiles: want=`" + want + "` current=`" + diff.String() + "`")
+		}
+		if _, err := os.Stat(staleFile); err != nil {
+			t.Errorf("removeStaleFiles: the file is removed: err=%v", err)
+		}
+	})
+}
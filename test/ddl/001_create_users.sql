@@ -0,0 +1,13 @@
+-- users
+CREATE TABLE IF NOT EXISTS `testdataset.users` (
+  user_id INT64 NOT NULL OPTIONS(description="user id"),
+  name STRING(64),
+  profile STRUCT<
+    age INT64,
+    tags ARRAY<STRING>
+  > NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP()
+)
+PARTITION BY DATE(created_at)
+CLUSTER BY user_id
+OPTIONS(description="users table");
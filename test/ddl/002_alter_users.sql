@@ -0,0 +1,6 @@
+ALTER TABLE testdataset.users ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE OR REPLACE TABLE testdataset.events (
+  event_id STRING NOT NULL,
+  amount NUMERIC(10, 2)
+);